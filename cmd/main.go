@@ -1,37 +1,105 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	"os"
-	"unix_grep_lite/internal/domain"
-	"unix_grep_lite/internal/usecase"
+	"runtime"
+	"strings"
+	"unix_grep_lite/grep"
 
 	"github.com/spf13/pflag"
 )
 
+// Коды возврата, как в GNU grep
+const (
+	exitMatch   = 0 // выбрана хотя бы одна строка
+	exitNoMatch = 1 // ни одна строка не выбрана
+	exitError   = 2 // произошла ошибка
+)
+
 func main() {
 	// flags init
 	numAfter := pflag.IntP("after-context", "A", 0, "Print num lines of trailing context after matching lines.")
 	numBefore := pflag.IntP("before-context", "B", 0, "Print num lines of leading context before matching lines.")
 	numAround := pflag.IntP("context", "C", 0, "Print num lines of leading and trailing output context.")
+	numMax := pflag.IntP("max-count", "m", 0, "Stop after the first num selected lines. If num is zero, stop right away without reading input. A num of -1 is treated as infinity.")
 	count := pflag.BoolP("count", "c", false, "Suppress normal output; instead print a count of matching lines for each input file.")
+	filesWithMatches := pflag.BoolP("files-with-matches", "l", false, "Suppress normal output; instead print the name of each input file from which output would normally have been printed.")
+	filesWithoutMatch := pflag.BoolP("files-without-match", "L", false, "Suppress normal output; instead print the name of each input file from which no output would normally have been printed.")
+	quiet := pflag.BoolP("quiet", "q", false, "Quiet; do not write anything to standard output. Exit immediately with zero status if any match is found, even if an error was detected.")
+	noMessages := pflag.BoolP("no-messages", "s", false, "Suppress error messages about nonexistent or unreadable files.")
 	ignoreCase := pflag.BoolP("ignore-case", "i", false, "Ignore case distinctions in patterns and input data, so that characters that differ only in case match each other.")
 	invertMatch := pflag.BoolP("invert-match", "v", false, "Invert the sense of matching, to select non-matching lines.")
 	fixedStrings := pflag.BoolP("fixed-strings", "F", false, "Interpret patterns as fixed strings, not regular expressions.")
+	engine := pflag.String("engine", "", "Use match engine NAME instead of the one chosen by -F and the number of patterns: "+strings.Join(grep.Engines(), ", ")+".")
+	wordRegexp := pflag.BoolP("word-regexp", "w", false, "Select only those lines containing matches that form whole words.")
+	lineRegexp := pflag.BoolP("line-regexp", "x", false, "Select only those matches that exactly match the whole line.")
 	lineNumber := pflag.BoolP("line-number", "n", false, "Prefix each line of output with the 1-based line number within its input file.")
+	onlyMatching := pflag.BoolP("only-matching", "o", false, "Print only the matched (non-empty) parts of a matching line, with each such part on a separate output line.")
+	byteOffset := pflag.BoolP("byte-offset", "b", false, "Print the 0-based byte offset within the input file before each line of output. If -o is specified, print the offset of the matching part itself.")
+	column := pflag.Bool("column", false, "Print the 1-based column number of the first match on each matching line.")
+	vimgrep := pflag.Bool("vimgrep", false, "Print every match as a separate file:line:column:text record, for loading into an editor quickfix list.")
+	jsonOutput := pflag.Bool("json", false, "Print results as JSON Lines: begin, match, context and end events. Cannot be combined with -c, -l or -L.")
+	withFilename := pflag.BoolP("with-filename", "H", false, "Print the file name for each match. This is the default when there is more than one file to search.")
+	noFilename := pflag.BoolP("no-filename", "h", false, "Suppress the prefixing of file names on output. This is the default when there is only one file to search.")
+	regexps := pflag.StringArrayP("regexp", "e", nil, "Use PATTERN as the pattern. If this option is used multiple times, search for all patterns given.")
+	patternFiles := pflag.StringArrayP("file", "f", nil, "Obtain patterns from FILE, one per line. The empty file contains zero patterns, and therefore matches nothing.")
+	recursive := pflag.BoolP("recursive", "r", false, "Read all files under each directory, recursively, following symbolic links only if they are on the command line.")
+	dereference := pflag.BoolP("dereference-recursive", "R", false, "Read all files under each directory, recursively. Follow all symbolic links.")
+	devices := pflag.StringP("devices", "D", "read", "If an input file is a device, FIFO or socket, use ACTION to process it: read or skip.")
+	binaryFiles := pflag.String("binary-files", "binary", "If a file's data or metadata indicate that the file contains binary data, assume that the file is of type TYPE: binary, text or without-match.")
+	text := pflag.BoolP("text", "a", false, "Process a binary file as if it were text; this is equivalent to --binary-files=text.")
+	noBinary := pflag.BoolP("binary-without-match", "I", false, "Process a binary file as if it did not contain matching data; this is equivalent to --binary-files=without-match.")
+	searchZip := pflag.BoolP("search-zip", "z", false, "Search in compressed files: gzip, bzip2, xz and zstd inputs are detected by their magic bytes and decompressed on the fly.")
+	noIgnore := pflag.Bool("no-ignore", false, "Do not respect .gitignore, .ignore and .git/info/exclude files when searching directories recursively.")
+	hidden := pflag.Bool("hidden", false, "Search hidden files and directories when searching directories recursively.")
+	include := pflag.StringArray("include", nil, "Search only files whose base name matches GLOB. If this option is used multiple times, a file matching any GLOB is searched.")
+	exclude := pflag.StringArray("exclude", nil, "Skip any file whose name suffix matches the pattern GLOB.")
+	excludeDir := pflag.StringArray("exclude-dir", nil, "When searching recursively, skip any subdirectory whose base name matches GLOB.")
+	fileTypes := pflag.StringArrayP("type", "t", nil, "Search only files of type TYPE (for example go or md). See --type-list.")
+	fileTypesNot := pflag.StringArrayP("type-not", "T", nil, "Do not search files of type TYPE.")
+	typeAdd := pflag.StringArray("type-add", nil, "Add globs to file type TYPE, in the form TYPE:GLOB[,GLOB...].")
+	typeList := pflag.Bool("type-list", false, "Print all known file types and their globs, then exit.")
+	maxDepth := pflag.Int("max-depth", 0, "Descend at most NUM levels of directories below the command line operands (0 means no limit).")
+	color := pflag.String("color", "never", "Surround the matched strings, lines, file names, line numbers and separators with escape sequences to display them in color. WHEN is never, always, or auto.")
+	pflag.Lookup("color").NoOptDefVal = "auto"
+	label := pflag.String("label", "(standard input)", "Display input actually coming from standard input as input coming from file LABEL.")
+	threads := pflag.IntP("threads", "j", runtime.NumCPU(), "Number of files to search in parallel. Output is still grouped per file in operand order.")
 
 	pflag.Parse()
 
-	opts := domain.GrepOptions{
-		NumAfter:     *numAfter,
-		NumBefore:    *numBefore,
-		NumAround:    *numAround,
-		Count:        *count,
-		IgnoreCase:   *ignoreCase,
-		InvertMatch:  *invertMatch,
-		FixedStrings: *fixedStrings,
-		LineNumber:   *lineNumber,
+	opts := grep.Options{
+		NumAfter:          *numAfter,
+		NumBefore:         *numBefore,
+		NumAround:         *numAround,
+		NumMax:            *numMax,
+		Count:             *count,
+		FilesWithMatches:  *filesWithMatches,
+		FilesWithoutMatch: *filesWithoutMatch,
+		Quiet:             *quiet,
+		IgnoreCase:        *ignoreCase,
+		InvertMatch:       *invertMatch,
+		FixedStrings:      *fixedStrings,
+		Engine:            *engine,
+		WordRegexp:        *wordRegexp,
+		LineRegexp:        *lineRegexp,
+		LineNumber:        *lineNumber || *vimgrep,
+		OnlyMatching:      *onlyMatching,
+		ByteOffset:        *byteOffset,
+		Column:            *column || *vimgrep,
+		Vimgrep:           *vimgrep,
+		JSON:              *jsonOutput,
+		Recursive:         *recursive || *dereference,
+		Dereference:       *dereference,
+		NoIgnore:          *noIgnore,
+		Hidden:            *hidden,
+		MaxDepth:          *maxDepth,
+		Include:           *include,
+		Exclude:           *exclude,
+		ExcludeDir:        *excludeDir,
+		Label:             *label,
+		SearchZip:         *searchZip,
 	}
 	pflag.Visit(func(f *pflag.Flag) {
 		if f.Name == "after-context" {
@@ -43,55 +111,170 @@ func main() {
 		if f.Name == "context" {
 			opts.AroundContext = true
 		}
+		if f.Name == "max-count" {
+			opts.MaxCount = true
+		}
 	})
 
 	args := pflag.Args()
 
-	var (
-		pattern, input string
-		b              []byte
-		err            error
-	)
-	switch {
-	case len(args) == 1:
-		// Читаем из stdin если файлы не указаны
-		b, err = io.ReadAll(os.Stdin)
-		if err != nil {
+	switch *color {
+	case "never":
+	case "always":
+		opts.Color = true
+	case "auto":
+		opts.Color = isTerminal(os.Stdout) && os.Getenv("TERM") != "dumb"
+	default:
+		fmt.Fprintln(os.Stderr, "Error:", grep.ErrUnknownColor)
+		os.Exit(exitError)
+	}
+	opts.Colors = grep.ParseColors(os.Getenv("GREP_COLORS"))
+
+	switch *devices {
+	case "read":
+	case "skip":
+		opts.SkipDevices = true
+	default:
+		fmt.Fprintln(os.Stderr, "Error:", grep.ErrUnknownDevices)
+		os.Exit(exitError)
+	}
+
+	switch *binaryFiles {
+	case "binary":
+	case "text":
+		opts.BinaryFiles = grep.BinaryFilesText
+	case "without-match":
+		opts.BinaryFiles = grep.BinaryFilesWithoutMatch
+	default:
+		fmt.Fprintln(os.Stderr, "Error:", grep.ErrUnknownBinaryFiles)
+		os.Exit(exitError)
+	}
+	if *text {
+		opts.BinaryFiles = grep.BinaryFilesText
+	}
+	if *noBinary {
+		opts.BinaryFiles = grep.BinaryFilesWithoutMatch
+	}
+
+	// Типы, добавленные --type-add, доступны для -t и -T
+	types := grep.DefaultFileTypes()
+	for _, def := range *typeAdd {
+		if err := types.Add(def); err != nil {
 			fmt.Fprintln(os.Stderr, "Error:", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
-	case len(args) == 2:
-		// Читаем из файла
-		file, err := os.Open(args[1])
-		if err != nil {
+	}
+	if *typeList {
+		if err := types.WriteList(os.Stdout); err != nil {
 			fmt.Fprintln(os.Stderr, "Error:", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
-		defer file.Close() //nolint:errcheck
+		os.Exit(exitMatch)
+	}
+	var err error
+	if opts.TypeGlobs, err = types.Globs(*fileTypes); err == nil {
+		opts.TypeNotGlobs, err = types.Globs(*fileTypesNot)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitError)
+	}
 
-		b, err = io.ReadAll(file)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error:", err)
-			os.Exit(1)
+	// Паттерн берётся из первого аргумента, только если не заданы -e и -f
+	patterns, err := readPatterns(*regexps, *patternFiles)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitError)
+	}
+	files := args
+	if len(*regexps) == 0 && len(*patternFiles) == 0 {
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Error:", grep.ErrWrongArgs)
+			os.Exit(exitError)
 		}
-	default:
-		fmt.Fprintln(os.Stderr, "Error:", domain.ErrWrongArgs)
-		os.Exit(1)
+		patterns, files = strings.Split(args[0], "\n"), args[1:]
 	}
 
-	pattern = args[0]
-	input = string(b)
+	// Префикс с именем файла по умолчанию выводится для нескольких файлов, при рекурсивном поиске
+	// и в формате --vimgrep
+	opts.WithFilename = len(files) > 1 || opts.Recursive || opts.Vimgrep
+	pflag.Visit(func(f *pflag.Flag) {
+		if f.Name == "with-filename" {
+			opts.WithFilename = *withFilename
+		}
+		if f.Name == "no-filename" {
+			opts.WithFilename = !*noFilename
+		}
+	})
 
-	matcher, err := usecase.NewMatcher(pattern, opts)
+	matcher, err := grep.CompilePatterns(patterns, opts)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "failed to create matcher: %w", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "failed to create matcher:", err)
+		os.Exit(exitError)
+	}
+
+	// Один файл без обхода каталогов ищется потоково, без накопления вывода
+	if !opts.Recursive && len(files) <= 1 {
+		*threads = 1
+	}
+
+	// Ошибки отдельных файлов не прерывают поиск, а влияют только на код возврата
+	failed := false
+	matched := matcher.SearchFiles(grep.Walk(files, opts), os.Stdout, *threads, func(err error) {
+		var fileErr *grep.FileError
+		if !*noMessages || !errors.As(err, &fileErr) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		if !errors.Is(err, grep.ErrRecursiveLoop) {
+			failed = true
+		}
+	})
+
+	// При -q найденное совпадение важнее ошибок
+	switch {
+	case matched && opts.Quiet:
+		os.Exit(exitMatch)
+	case failed:
+		os.Exit(exitError)
+	case !matched:
+		os.Exit(exitNoMatch)
+	}
+}
+
+// readPatterns собирает паттерны из флагов -e и файлов -f, "-" означает stdin
+func readPatterns(regexps, patternFiles []string) ([]string, error) {
+	patterns := make([]string, 0, len(regexps))
+	// Паттерн -e с переводами строк задаёт несколько паттернов
+	for _, re := range regexps {
+		patterns = append(patterns, strings.Split(re, "\n")...)
+	}
+	for _, name := range patternFiles {
+		filePatterns, err := readPatternFile(name)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, filePatterns...)
 	}
-	result, err := matcher.SearchMatch(pattern, input, opts)
+	return patterns, nil
+}
+
+// readPatternFile читает паттерны из файла name по одному на строку
+func readPatternFile(name string) ([]string, error) {
+	if name == grep.StdinOperand {
+		return grep.ReadPatterns(os.Stdin)
+	}
+
+	file, err := os.Open(name)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
-		os.Exit(1)
+		return nil, err
 	}
+	defer file.Close() //nolint:errcheck
+
+	return grep.ReadPatterns(file)
+}
 
-	fmt.Println(result)
+// isTerminal проверяет, является ли файл терминалом
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
@@ -3,8 +3,10 @@ module unix_grep_lite
 go 1.24.2
 
 require (
+	github.com/klauspost/compress v1.18.0
 	github.com/spf13/pflag v1.0.10
 	github.com/stretchr/testify v1.11.1
+	github.com/ulikunitz/xz v0.5.15
 )
 
 require (
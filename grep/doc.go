@@ -0,0 +1,27 @@
+// Package grep - библиотека поиска строк по паттернам с семантикой GNU grep,
+// на которой построена утилита unix_grep_lite.
+//
+// Набор паттернов компилируется один раз функцией Compile или CompilePatterns
+// с параметрами Options, после чего Matcher можно использовать для любого числа
+// входов, в том числе из нескольких горутин одновременно:
+//
+//   - Matcher.Matches возвращает выбранные и контекстные строки в виде структур Match,
+//     а Matcher.Count - число выбранных строк;
+//   - Matcher.All перечисляет те же результаты лениво, не читая вход дальше,
+//     чем запросил получатель;
+//   - Matcher.Search и Matcher.SearchFile пишут результат в формате утилиты
+//     (префиксы, контекст, -c, -l, --json и т. д.);
+//   - Matcher.SearchFormat передаёт результаты собственной реализации Formatter;
+//   - Matcher.SearchFiles ищет по файлам, перечисленным Walk, параллельно,
+//     сохраняя порядок вывода.
+//
+// # Совместимость
+//
+// Пакет следует семантическому версионированию модуля. В пределах одной
+// старшей версии экспортированные функции, методы и типы не удаляются и не
+// меняют сигнатур, а смысл существующих полей Options и Match не меняется;
+// новые поля и функции могут добавляться, поэтому структуры следует заполнять
+// по именам полей. Текстовые форматы вывода (включая схему --json) меняются
+// только добавлением новых данных. Сообщения ошибок не входят в гарантии:
+// для проверки используйте errors.Is и errors.As с экспортированными ошибками.
+package grep
@@ -0,0 +1,124 @@
+package grep_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"unix_grep_lite/grep"
+)
+
+func ExampleMatcher_Matches() {
+	m, err := grep.Compile(`ERROR \w+`, grep.Options{IgnoreCase: true})
+	if err != nil {
+		panic(err)
+	}
+
+	input := "ok\nerror disk full\nok\nERROR timeout\n"
+	matches, err := m.Matches(strings.NewReader(input))
+	if err != nil {
+		panic(err)
+	}
+	for _, match := range matches {
+		s := match.Submatches[0]
+		fmt.Printf("%d: %q\n", match.LineNumber, match.Text[s.Start:s.End])
+	}
+	// Output:
+	// 2: "error disk"
+	// 4: "ERROR timeout"
+}
+
+func ExampleMatcher_Search() {
+	m, err := grep.CompilePatterns([]string{"foo", "bar"}, grep.Options{LineNumber: true})
+	if err != nil {
+		panic(err)
+	}
+
+	if err := m.Search(strings.NewReader("foo\nbaz\nbar\n"), os.Stdout); err != nil {
+		panic(err)
+	}
+	// Output:
+	// 1:foo
+	// 3:bar
+}
+
+func ExampleMatcher_SearchFile() {
+	m, err := grep.Compile("timeout", grep.Options{WithFilename: true, AfterContext: true, NumAfter: 1})
+	if err != nil {
+		panic(err)
+	}
+
+	input := "start\nread timeout\nretry\ndone\n"
+	if err := m.SearchFile("app.log", strings.NewReader(input), os.Stdout); err != nil {
+		panic(err)
+	}
+	// Output:
+	// app.log:read timeout
+	// app.log-retry
+}
+
+func ExampleMatcher_Count() {
+	m, err := grep.Compile("foo", grep.Options{})
+	if err != nil {
+		panic(err)
+	}
+
+	count, err := m.Count(strings.NewReader("foo\nbar\nfoo bar\n"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(count.Lines)
+	// Output:
+	// 2
+}
+
+// lineNumbers форматировщик, выводящий только номера выбранных строк
+type lineNumbers struct {
+	grep.Formatter // остальные методы делегируются стандартному форматировщику
+}
+
+func (f lineNumbers) Match(match grep.Match) error {
+	if match.Kind == grep.KindMatch {
+		fmt.Println(match.LineNumber)
+	}
+	return nil
+}
+
+func ExampleMatcher_SearchFormat() {
+	opts := grep.Options{}
+	m, err := grep.Compile("foo", opts)
+	if err != nil {
+		panic(err)
+	}
+
+	f := lineNumbers{grep.NewFormatter(os.Stdout, "", opts)}
+	if err := m.SearchFormat("", strings.NewReader("foo\nbar\nfoo\n"), f); err != nil {
+		panic(err)
+	}
+	// Output:
+	// 1
+	// 3
+}
+
+func ExampleMatcher_All() {
+	m, err := grep.Compile("ERROR", grep.Options{})
+	if err != nil {
+		panic(err)
+	}
+
+	// Как head -n 2: после второго совпадения вход дальше не читается
+	input := strings.NewReader("ERROR a\nok\nERROR b\nERROR c\n")
+	n := 0
+	for match, err := range m.All(input) {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(match.LineNumber, match.Text)
+		if n++; n == 2 {
+			break
+		}
+	}
+	// Output:
+	// 1 ERROR a
+	// 3 ERROR b
+}
@@ -0,0 +1,212 @@
+package grep
+
+import (
+	"io"
+	"iter"
+	"unix_grep_lite/internal/domain"
+	"unix_grep_lite/internal/usecase"
+)
+
+// Типы параметров и результатов поиска
+type (
+	// Options параметры поиска; нулевое значение - поиск регулярного выражения без дополнительных флагов.
+	// Положительные NumAfter, NumBefore, NumAround и NumMax сами включают контекст и ограничение -m;
+	// флаги AfterContext, BeforeContext, AroundContext и MaxCount нужны только для нулевых значений
+	// (-A 0, -m 0 и т. д.).
+	Options = domain.GrepOptions
+	// Colors SGR-параметры подсветки вывода (GREP_COLORS)
+	Colors = domain.Colors
+	// BinaryFiles способ обработки бинарных входов
+	BinaryFiles = domain.BinaryFiles
+	// Match строка входа в результатах поиска с границами совпадений
+	Match = domain.Match
+	// MatchKind вид строки в результатах: выбранная или контекстная
+	MatchKind = domain.MatchKind
+	// Span границы совпадения в байтах от начала строки
+	Span = domain.Span
+	// Count результат подсчёта выбранных строк
+	Count = domain.Count
+	// Formatter форматировщик результатов поиска по одному входу
+	Formatter = usecase.Formatter
+	// MatchEngine алгоритм поиска совпадений набора паттернов в строке
+	MatchEngine = usecase.MatchEngine
+	// EngineFactory конструктор движка для RegisterEngine
+	EngineFactory = usecase.EngineFactory
+	// FileTypes реестр типов файлов для фильтров Options.TypeGlobs и Options.TypeNotGlobs
+	FileTypes = usecase.FileTypes
+)
+
+// Виды строк в результатах поиска
+const (
+	KindMatch   = domain.KindMatch
+	KindContext = domain.KindContext
+)
+
+// Способы обработки бинарных входов
+const (
+	BinaryFilesBinary       = domain.BinaryFilesBinary
+	BinaryFilesText         = domain.BinaryFilesText
+	BinaryFilesWithoutMatch = domain.BinaryFilesWithoutMatch
+)
+
+// Имена встроенных движков для Options.Engine
+const (
+	EngineRE2         = usecase.EngineRE2
+	EngineFixed       = usecase.EngineFixed
+	EngineAhoCorasick = usecase.EngineAhoCorasick
+)
+
+// StdinOperand операнд Walk, обозначающий стандартный ввод
+const StdinOperand = usecase.StdinOperand
+
+// Ошибки с типом, сообщающие подробности
+type (
+	// PatternError ошибка компиляции паттерна
+	PatternError = domain.PatternError
+	// FileError ошибка доступа к входному файлу или его чтения
+	FileError = domain.FileError
+	// WriteError ошибка записи результатов поиска
+	WriteError = domain.WriteError
+)
+
+// Ошибки, которые можно проверить через errors.Is
+var (
+	ErrInvalidContextLength = domain.ErrInvalidContextLength
+	ErrWrongArgs            = domain.ErrWrongArgs
+	ErrUnknownDevices       = domain.ErrUnknownDevices
+	ErrUnknownColor         = domain.ErrUnknownColor
+	ErrUnknownBinaryFiles   = domain.ErrUnknownBinaryFiles
+	ErrUnknownType          = domain.ErrUnknownType
+	ErrInvalidTypeDef       = domain.ErrInvalidTypeDef
+	ErrUnknownEngine        = domain.ErrUnknownEngine
+	ErrJSONConflict         = domain.ErrJSONConflict
+	ErrIsDirectory          = domain.ErrIsDirectory
+	ErrRecursiveLoop        = domain.ErrRecursiveLoop
+)
+
+// Matcher скомпилированный набор паттернов с параметрами поиска
+type Matcher struct {
+	m *usecase.Matcher
+}
+
+// Compile компилирует паттерн; паттерн с переводами строк задаёт набор паттернов
+func Compile(pattern string, opts Options) (*Matcher, error) {
+	m, err := usecase.NewMatcher(pattern, withCounts(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{m: m}, nil
+}
+
+// CompilePatterns компилирует набор паттернов: строка выбирается, если совпал хотя бы один.
+// Пустой паттерн совпадает с любой строкой, пустой набор - ни с одной.
+// Некорректный паттерн возвращает *PatternError.
+func CompilePatterns(patterns []string, opts Options) (*Matcher, error) {
+	m, err := usecase.NewMatcherPatterns(patterns, withCounts(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{m: m}, nil
+}
+
+// withCounts включает контекст и ограничение -m по положительным значениям NumAfter, NumBefore,
+// NumAround и NumMax, чтобы Options{NumAfter: 2} работал без AfterContext.
+// Явные флаги сохраняются: утилита задаёт их для нулевых значений вроде -A 0 и -m 0.
+func withCounts(opts Options) Options {
+	opts.AfterContext = opts.AfterContext || opts.NumAfter > 0
+	opts.BeforeContext = opts.BeforeContext || opts.NumBefore > 0
+	opts.AroundContext = opts.AroundContext || opts.NumAround > 0
+	opts.MaxCount = opts.MaxCount || opts.NumMax > 0
+	return opts
+}
+
+// Matches читает r и возвращает выбранные строки, а при заданном контексте
+// (Options.NumAfter и т. д.) - и строки контекста с Kind == KindContext.
+// Учитываются параметры сопоставления и ограничение Options.NumMax;
+// режимы вывода (Count, FilesWithMatches, OnlyMatching, JSON и т. д.) не влияют на результат.
+func (m *Matcher) Matches(r io.Reader) ([]Match, error) {
+	return m.m.Matches(r)
+}
+
+// All лениво перечисляет те же результаты, что и Matches: строки читаются из r
+// по мере запроса, а при выходе из цикла range чтение прекращается. Ошибка чтения
+// передаётся последней парой с нулевым Match.
+//
+//	for match, err := range m.All(r) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (m *Matcher) All(r io.Reader) iter.Seq2[Match, error] {
+	return m.m.All(r)
+}
+
+// Count читает r и подсчитывает выбранные строки с учётом ограничения Options.NumMax
+func (m *Matcher) Count(r io.Reader) (Count, error) {
+	return m.m.Count(r)
+}
+
+// SearchFormat ищет в r как SearchFile, передавая результаты форматировщику f
+// в соответствии с режимом Options (контекст, Count, FilesWithMatches и т. д.).
+// Границы совпадений в результатах заполняются всегда.
+func (m *Matcher) SearchFormat(name string, r io.Reader, f Formatter) error {
+	return m.m.SearchFormat(name, r, f)
+}
+
+// Search потоково ищет в r и пишет результат в w в формате утилиты
+func (m *Matcher) Search(r io.Reader, w io.Writer) error {
+	return m.m.Search(r, w)
+}
+
+// SearchFile как Search, но использует name как имя входа в выводе.
+// Ошибки чтения возвращаются как *FileError, ошибки записи - как *WriteError.
+func (m *Matcher) SearchFile(name string, r io.Reader, w io.Writer) error {
+	return m.m.SearchFile(name, r, w)
+}
+
+// SearchFiles ищет по файлам files (обычно из Walk) в threads потоков и пишет результат в w
+// в порядке files. Ошибки отдельных файлов передаются onErr, поиск при этом продолжается.
+// Возвращает true, если хотя бы в одном файле была выбрана строка.
+func (m *Matcher) SearchFiles(files iter.Seq2[string, error], w io.Writer, threads int, onErr func(error)) bool {
+	return m.m.SearchFiles(files, w, threads, onErr)
+}
+
+// RegisterEngine регистрирует движок под именем name; он используется, если Options.Engine == name.
+// Движок с тем же именем заменяется, а его методы получают собственные копии строк входа.
+// Функцию следует вызывать до компиляции паттернов, например в init.
+func RegisterEngine(name string, factory EngineFactory) {
+	usecase.RegisterEngine(name, factory)
+}
+
+// Engines возвращает имена зарегистрированных движков в алфавитном порядке
+func Engines() []string {
+	return usecase.Engines()
+}
+
+// NewFormatter создаёт форматировщик вывода утилиты для входа name:
+// JSON Lines при Options.JSON, иначе текст в формате GNU grep
+func NewFormatter(w io.Writer, name string, opts Options) Formatter {
+	return usecase.NewFormatter(w, name, withCounts(opts))
+}
+
+// Walk перечисляет файлы для поиска по операндам командной строки с учётом рекурсивного
+// обхода, файлов правил исключения и фильтров Options. Ошибки доступа передаются как *FileError.
+func Walk(operands []string, opts Options) iter.Seq2[string, error] {
+	return usecase.WalkFiles(operands, opts)
+}
+
+// ParseColors разбирает значение переменной GREP_COLORS поверх цветов по умолчанию
+func ParseColors(s string) Colors {
+	return usecase.ParseGrepColors(s)
+}
+
+// ReadPatterns читает паттерны из r, по одному на строку
+func ReadPatterns(r io.Reader) ([]string, error) {
+	return usecase.ReadPatterns(r)
+}
+
+// DefaultFileTypes возвращает копию реестра встроенных типов файлов
+func DefaultFileTypes() FileTypes {
+	return usecase.DefaultFileTypes()
+}
@@ -0,0 +1,78 @@
+package grep_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"unix_grep_lite/grep"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePatternError(t *testing.T) {
+	t.Parallel()
+
+	_, err := grep.CompilePatterns([]string{"ok", "("}, grep.Options{})
+	var patternErr *grep.PatternError
+	require.ErrorAs(t, err, &patternErr)
+	require.Equal(t, "(", patternErr.Pattern)
+}
+
+func TestCompileConflict(t *testing.T) {
+	t.Parallel()
+
+	_, err := grep.Compile("foo", grep.Options{JSON: true, Count: true})
+	require.ErrorIs(t, err, grep.ErrJSONConflict)
+}
+
+func TestMatcherSearchFileReadError(t *testing.T) {
+	t.Parallel()
+
+	m, err := grep.Compile("foo", grep.Options{})
+	require.NoError(t, err)
+
+	readErr := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("foo\n"), iotest.ErrReader(readErr))
+	err = m.SearchFile("a.txt", r, io.Discard)
+
+	var fileErr *grep.FileError
+	require.ErrorAs(t, err, &fileErr)
+	require.Equal(t, "a.txt", fileErr.Path)
+	require.ErrorIs(t, err, readErr)
+}
+
+func TestOptionsCounts(t *testing.T) {
+	t.Parallel()
+
+	const input = "foo\nbar\nbaz\nfoo\nqux\nfoo\n"
+
+	tests := []struct {
+		name     string
+		opts     grep.Options
+		expected string
+	}{
+		{name: "after context", opts: grep.Options{NumAfter: 1}, expected: "foo\nbar\n--\nfoo\nqux\nfoo\n"},
+		{name: "before context", opts: grep.Options{NumBefore: 1}, expected: "foo\n--\nbaz\nfoo\nqux\nfoo\n"},
+		{name: "around context", opts: grep.Options{NumAround: 1}, expected: "foo\nbar\nbaz\nfoo\nqux\nfoo\n"},
+		{name: "max count", opts: grep.Options{NumMax: 2}, expected: "foo\nfoo\n"},
+		{name: "negative max count", opts: grep.Options{NumMax: -1}, expected: "foo\nfoo\nfoo\n"},
+		{name: "explicit zero context", opts: grep.Options{AfterContext: true}, expected: "foo\n--\nfoo\n--\nfoo\n"},
+		{name: "explicit zero max count", opts: grep.Options{MaxCount: true}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := grep.Compile("foo", tt.opts)
+			require.NoError(t, err)
+
+			var out strings.Builder
+			require.NoError(t, m.Search(strings.NewReader(input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
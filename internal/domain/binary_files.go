@@ -0,0 +1,10 @@
+package domain
+
+// BinaryFiles способ обработки бинарных входов (флаги --binary-files, -a и -I)
+type BinaryFiles int
+
+const (
+	BinaryFilesBinary       BinaryFiles = iota // вместо строк выводится сообщение "Binary file X matches"
+	BinaryFilesText                            // -a: вход обрабатывается как текст
+	BinaryFilesWithoutMatch                    // -I: вход считается не содержащим совпадений
+)
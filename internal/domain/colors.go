@@ -0,0 +1,15 @@
+package domain
+
+// Colors SGR-параметры подсветки вывода (флаг --color, переменная GREP_COLORS).
+// Пустое значение отключает подсветку соответствующего элемента.
+type Colors struct {
+	SelectedMatch string // ms: совпадение в выбранной строке
+	ContextMatch  string // mc: совпадение в контекстной строке
+	SelectedLine  string // sl: выбранная строка целиком
+	ContextLine   string // cx: контекстная строка целиком
+	FileName      string // fn: имя файла
+	LineNumber    string // ln: номер строки
+	Separator     string // se: разделители ':' и "--"
+	Reverse       bool   // rv: поменять местами sl и cx при -v
+	NoEraseLine   bool   // ne: не добавлять очистку до конца строки \033[K
+}
@@ -1,8 +1,71 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"io/fs"
+)
 
 var (
 	ErrInvalidContextLength = errors.New("grep: invalid context length argument")
 	ErrWrongArgs            = errors.New("grep: wrong arguments")
+	ErrUnknownDevices       = errors.New("grep: unknown devices method")
+	ErrUnknownColor         = errors.New("grep: invalid argument for --color, valid arguments are 'always', 'never' and 'auto'")
+	ErrUnknownBinaryFiles   = errors.New("grep: unknown binary-files type")
+	ErrUnknownType          = errors.New("grep: unknown file type")
+	ErrInvalidTypeDef       = errors.New("grep: invalid file type definition, expected NAME:GLOB[,GLOB...]")
+	ErrUnknownEngine        = errors.New("grep: unknown match engine")
+	ErrJSONConflict         = errors.New("grep: --json cannot be combined with -c, -l or -L")
+	ErrIsDirectory          = errors.New("is a directory")
+	ErrRecursiveLoop        = errors.New("warning: recursive directory loop")
 )
+
+// PatternError ошибка компиляции паттерна
+type PatternError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *PatternError) Error() string {
+	return "invalid regexp pattern '" + e.Pattern + "': " + e.Err.Error()
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+// FileError ошибка доступа к входному файлу или его чтения.
+// Поиск по остальным файлам после неё продолжается.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// NewFileError оборачивает ошибку доступа к файлу path, убирая дублирующий путь из *fs.PathError
+func NewFileError(path string, err error) *FileError {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && pathErr.Path == path {
+		err = pathErr.Err
+	}
+	return &FileError{Path: path, Err: err}
+}
+
+func (e *FileError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError ошибка записи результатов поиска, после которой поиск прекращается
+type WriteError struct {
+	Err error
+}
+
+func (e *WriteError) Error() string {
+	return "write error: " + e.Err.Error()
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
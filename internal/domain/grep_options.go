@@ -1,15 +1,45 @@
 package domain
 
 type GrepOptions struct {
-	NumAfter      int
-	NumBefore     int
-	NumAround     int
-	AfterContext  bool
-	BeforeContext bool
-	AroundContext bool
-	Count         bool
-	IgnoreCase    bool
-	InvertMatch   bool
-	FixedStrings  bool
-	LineNumber    bool
+	NumAfter          int  // -A: строк контекста после совпадения; положительное значение включает контекст
+	NumBefore         int  // -B: строк контекста перед совпадением; положительное значение включает контекст
+	NumAround         int  // -C: строк контекста с обеих сторон; положительное значение включает контекст
+	NumMax            int  // -m: предел числа выбранных строк; положительное значение включает ограничение
+	AfterContext      bool // -A задан явно; нужен только для -A 0 (разделители групп без строк контекста)
+	BeforeContext     bool // -B задан явно; нужен только для -B 0
+	AroundContext     bool // -C задан явно; нужен только для -C 0
+	MaxCount          bool // -m задан явно; нужен только для -m 0 (ничего не читать), отрицательный NumMax - без ограничения
+	Count             bool
+	FilesWithMatches  bool // -l: вывод только имён файлов с совпадениями
+	FilesWithoutMatch bool // -L: вывод только имён файлов без совпадений
+	Quiet             bool // -q: без вывода, поиск прекращается на первом совпадении
+	IgnoreCase        bool
+	InvertMatch       bool
+	FixedStrings      bool
+	WordRegexp        bool // -w: совпадение должно быть целым словом
+	LineRegexp        bool // -x: совпадение должно занимать всю строку
+	LineNumber        bool
+	OnlyMatching      bool // -o: вывод только совпавших частей строк
+	ByteOffset        bool // -b: смещение строки (или совпадения при -o) в байтах
+	Column            bool // --column: колонка первого совпадения (начиная с 1)
+	Vimgrep           bool // --vimgrep: отдельная запись file:line:col:text на каждое совпадение
+	JSON              bool // --json: события в формате JSON Lines
+	WithFilename      bool
+	Color             bool        // --color: подсветка совпадений
+	Colors            Colors      // цвета подсветки (GREP_COLORS)
+	Label             string      // --label: имя stdin в выводе
+	BinaryFiles       BinaryFiles // --binary-files, -a, -I: обработка бинарных входов
+	SearchZip         bool        // -z: распаковка сжатых входов gzip, bzip2, xz и zstd
+	Engine            string      // --engine: имя движка сопоставления, пустое - выбор по флагам
+	Recursive         bool        // -r/-R: обход каталогов
+	Dereference       bool        // -R: переход по всем символическим ссылкам
+	SkipDevices       bool        // -D skip: пропуск устройств, FIFO и сокетов
+	NoIgnore          bool        // --no-ignore: не учитывать .gitignore, .ignore и .git/info/exclude
+	Hidden            bool        // --hidden: обходить скрытые файлы и каталоги
+	MaxDepth          int         // --max-depth: глубина обхода каталогов-операндов, 0 - без ограничения
+	Include           []string    // --include: поиск только в файлах, имена которых совпадают с шаблонами
+	Exclude           []string    // --exclude: пропуск файлов, имена которых совпадают с шаблонами
+	ExcludeDir        []string    // --exclude-dir: пропуск вложенных каталогов при обходе
+	TypeGlobs         []string    // -t: шаблоны имён выбранных типов файлов
+	TypeNotGlobs      []string    // -T: шаблоны имён исключённых типов файлов
 }
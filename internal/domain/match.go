@@ -0,0 +1,31 @@
+package domain
+
+// MatchKind вид строки в результатах поиска
+type MatchKind int
+
+const (
+	KindMatch   MatchKind = iota // выбранная строка
+	KindContext                  // строка контекста (-A, -B, -C)
+)
+
+// Match строка входа в результатах поиска с границами совпадений в ней
+type Match struct {
+	Kind       MatchKind
+	LineNumber int    // номер строки (начиная с 1)
+	Offset     int64  // смещение начала строки в байтах от начала входа
+	Text       string // текст строки без перевода строки
+	// Совпадения паттернов в строке слева направо. Заполняются для выбранных строк,
+	// а при -v - для контекстных, так как только они содержат совпадения.
+	Submatches []Span
+}
+
+// Span границы совпадения в байтах от начала строки: Text[Start:End]
+type Span struct {
+	Start int
+	End   int
+}
+
+// Count результат подсчёта (флаг -c)
+type Count struct {
+	Lines int // число выбранных строк
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"slices"
+	"unix_grep_lite/internal/domain"
+)
+
+// ahoCorasickMinPatterns число паттернов -F, начиная с которого по умолчанию
+// используется автомат Ахо-Корасик вместо поиска каждого паттерна отдельно
+const ahoCorasickMinPatterns = 8
+
+// ahoCorasickEngine движок множества фиксированных строк (флаг -F): строка просматривается
+// за один проход по детерминированному автомату независимо от числа паттернов.
+// Семантика совпадает с fixedEngine, включая -i, -w, -x и выбор самого левого, а из них
+// самого длинного совпадения.
+type ahoCorasickEngine struct {
+	classes    [256]int32 // класс байта; 0 - байт не встречается в паттернах
+	numClasses int
+	delta      []int32 // переходы автомата: delta[state*numClasses+class]
+	depth      []int32 // длина строки, соответствующей состоянию
+	terminal   []bool  // состояние соответствует паттерну
+	dict       []int32 // ближайшее терминальное состояние по суффиксным ссылкам, -1 - нет
+	maxLen     int     // длина самого длинного паттерна
+
+	patterns   []string            // уникальные паттерны, при -i приведены функцией foldCase
+	lines      map[string]struct{} // паттерны для -x
+	empty      MatchEngine         // движок для пустого паттерна, nil - его нет
+	ignoreCase bool
+	word       bool // -w
+}
+
+func newAhoCorasickEngine(patterns []string, opts domain.GrepOptions) (MatchEngine, error) {
+	e := &ahoCorasickEngine{ignoreCase: opts.IgnoreCase, word: opts.WordRegexp}
+
+	seen := make(map[string]struct{}, len(patterns))
+	for _, pattern := range patterns {
+		if opts.IgnoreCase {
+			pattern, _ = foldCase(pattern, false)
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		e.patterns = append(e.patterns, pattern)
+	}
+
+	// При -x совпадение - строка целиком, автомат не нужен
+	if opts.LineRegexp {
+		e.lines = seen
+		return e, nil
+	}
+	if _, ok := seen[""]; ok {
+		empty, err := newFixedEngine([]string{""}, opts)
+		if err != nil {
+			return nil, err
+		}
+		e.empty = empty
+	}
+	e.build()
+	return e, nil
+}
+
+// build строит автомат: бор паттернов, дополненный переходами по суффиксным ссылкам
+func (e *ahoCorasickEngine) build() {
+	// Байты, не встречающиеся в паттернах, объединяются в класс 0, что сокращает таблицу переходов
+	e.numClasses = 1
+	for _, pattern := range e.patterns {
+		for i := 0; i < len(pattern); i++ {
+			if e.classes[pattern[i]] == 0 {
+				e.classes[pattern[i]] = int32(e.numClasses)
+				e.numClasses++
+			}
+		}
+	}
+
+	e.addState(0)
+	for _, pattern := range e.patterns {
+		if pattern == "" {
+			continue
+		}
+		state := int32(0)
+		for i := 0; i < len(pattern); i++ {
+			idx := int(state)*e.numClasses + int(e.classes[pattern[i]])
+			if e.delta[idx] == 0 {
+				e.delta[idx] = e.addState(int32(i + 1))
+			}
+			state = e.delta[idx]
+		}
+		e.terminal[state] = true
+		e.maxLen = max(e.maxLen, len(pattern))
+	}
+
+	// Обход в ширину: до обработки состояния его ненулевые переходы ведут только к детям в боре
+	fail := make([]int32, len(e.depth))
+	queue := make([]int32, 0, len(e.depth))
+	for c := 1; c < e.numClasses; c++ {
+		if child := e.delta[c]; child != 0 {
+			queue = append(queue, child)
+		}
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		f := fail[u]
+		if e.terminal[f] {
+			e.dict[u] = f
+		} else {
+			e.dict[u] = e.dict[f]
+		}
+		for c := 1; c < e.numClasses; c++ {
+			idx := int(u)*e.numClasses + c
+			fallback := e.delta[int(f)*e.numClasses+c]
+			if child := e.delta[idx]; child != 0 {
+				fail[child] = fallback
+				queue = append(queue, child)
+			} else {
+				e.delta[idx] = fallback
+			}
+		}
+	}
+}
+
+// addState добавляет состояние с длиной строки depth и возвращает его номер
+func (e *ahoCorasickEngine) addState(depth int32) int32 {
+	e.delta = append(e.delta, make([]int32, e.numClasses)...)
+	e.depth = append(e.depth, depth)
+	e.terminal = append(e.terminal, false)
+	e.dict = append(e.dict, -1)
+	return int32(len(e.depth) - 1)
+}
+
+func (e *ahoCorasickEngine) Match(line string) bool {
+	if e.lines != nil {
+		if e.ignoreCase {
+			line, _ = foldCase(line, false)
+		}
+		_, ok := e.lines[line]
+		return ok
+	}
+	if e.empty != nil && e.empty.Match(line) {
+		return true
+	}
+	if e.ignoreCase {
+		line, _ = foldCase(line, false)
+	}
+	if e.word {
+		start, _ := e.leftmostLongest(line, 0)
+		return start >= 0
+	}
+
+	// Без -w достаточно любого вхождения
+	state := int32(0)
+	for i := 0; i < len(line); i++ {
+		state = e.delta[int(state)*e.numClasses+int(e.classes[line[i]])]
+		if e.terminal[state] || e.dict[state] >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ahoCorasickEngine) Find(line string) []int {
+	if e.lines != nil {
+		if line != "" && e.Match(line) {
+			return []int{0, len(line)}
+		}
+		return nil
+	}
+	var offs []int
+	if e.ignoreCase {
+		line, offs = foldCase(line, true)
+	}
+	start, end := e.leftmostLongest(line, 0)
+	if start < 0 {
+		return nil
+	}
+	return unfoldLocs([][]int{{start, end}}, offs)[0]
+}
+
+func (e *ahoCorasickEngine) FindAll(line string) [][]int {
+	if e.lines != nil {
+		if loc := e.Find(line); loc != nil {
+			return [][]int{loc}
+		}
+		return nil
+	}
+	var offs []int
+	if e.ignoreCase {
+		line, offs = foldCase(line, true)
+	}
+	var matches [][]int
+	for pos := 0; pos < len(line); {
+		start, end := e.leftmostLongest(line, pos)
+		if start < 0 {
+			break
+		}
+		matches = append(matches, []int{start, end})
+		pos = end
+	}
+	return unfoldLocs(matches, offs)
+}
+
+// leftmostLongest возвращает самое левое, а из них самое длинное вхождение паттернов
+// с позиции pos, удовлетворяющее -w, или -1, -1. Просмотр прекращается, как только
+// вхождения, заканчивающиеся дальше, не могут начинаться левее найденного.
+func (e *ahoCorasickEngine) leftmostLongest(line string, pos int) (start, end int) {
+	start, end = -1, -1
+	state := int32(0)
+	for i := pos; i < len(line); i++ {
+		if start >= 0 && i-e.maxLen >= start {
+			break
+		}
+		state = e.delta[int(state)*e.numClasses+int(e.classes[line[i]])]
+		s := state
+		if !e.terminal[s] {
+			s = e.dict[s]
+		}
+		// Все паттерны, заканчивающиеся в позиции i, от самого длинного к короткому
+		for ; s > 0; s = e.dict[s] {
+			j := i + 1
+			st := j - int(e.depth[s])
+			if start >= 0 && (st > start || st == start && j <= end) {
+				continue
+			}
+			if e.word && !isWordBounded(line, st, j) {
+				continue
+			}
+			start, end = st, j
+		}
+	}
+	return start, end
+}
+
+func (e *ahoCorasickEngine) LiteralPrefixes() []string {
+	// При -i совпадение может отличаться от паттерна регистром, пустой паттерн совпадает везде
+	if e.ignoreCase || slices.Contains(e.patterns, "") {
+		return nil
+	}
+	return e.patterns
+}
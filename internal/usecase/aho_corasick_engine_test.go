@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fixedOptsSet сочетания флагов сопоставления фиксированных строк
+var fixedOptsSet = []domain.GrepOptions{
+	{FixedStrings: true},
+	{FixedStrings: true, IgnoreCase: true},
+	{FixedStrings: true, WordRegexp: true},
+	{FixedStrings: true, LineRegexp: true},
+	{FixedStrings: true, IgnoreCase: true, WordRegexp: true},
+}
+
+// requireSameEngines проверяет, что движки дают одинаковые результаты на строках lines
+func requireSameEngines(t *testing.T, want, got MatchEngine, lines []string, msgArgs ...any) {
+	t.Helper()
+	for _, line := range lines {
+		args := append([]any{"line=%q %v", line}, msgArgs...)
+		require.Equal(t, want.Match(line), got.Match(line), args...)
+		require.Equal(t, want.Find(line), got.Find(line), args...)
+		require.Equal(t, normalizeLocs(want.FindAll(line)), normalizeLocs(got.FindAll(line)), args...)
+	}
+}
+
+func TestAhoCorasickAgreesWithFixed(t *testing.T) {
+	patterns := [][]string{
+		{"foo"},
+		{"foo", "foobar"},
+		{"bar", ""},
+		{"he", "she", "his", "hers"},
+		{"o", "oo", "foo", "ba", "bar"},
+		{"привет", "ПРИВ", "вет"},
+		{"foo", "foo", "FOO"},
+		{"kelvin", "secret", "straße"},
+	}
+	lines := append([]string{"ushers his", "she sells", "ahishers", "foofoo foo_", "ПРИВЕТ привет"}, engineLines...)
+
+	for _, ps := range patterns {
+		for _, opts := range fixedOptsSet {
+			fixed, err := newFixedEngine(ps, opts)
+			require.NoError(t, err)
+			ac, err := newAhoCorasickEngine(ps, opts)
+			require.NoError(t, err)
+
+			requireSameEngines(t, fixed, ac, lines, ps, opts)
+		}
+	}
+}
+
+func TestAhoCorasickRandom(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	// Малый алфавит с разделителем слов даёт много пересекающихся вхождений
+	randString := func(maxLen int) string {
+		const alphabet = "abAB _"
+		b := make([]byte, rnd.IntN(maxLen+1))
+		for i := range b {
+			b[i] = alphabet[rnd.IntN(len(alphabet))]
+		}
+		return string(b)
+	}
+
+	for range 200 {
+		patterns := make([]string, 1+rnd.IntN(20))
+		for i := range patterns {
+			patterns[i] = randString(4)
+		}
+		lines := make([]string, 20)
+		for i := range lines {
+			lines[i] = randString(30)
+		}
+
+		for _, opts := range fixedOptsSet {
+			fixed, err := newFixedEngine(patterns, opts)
+			require.NoError(t, err)
+			ac, err := newAhoCorasickEngine(patterns, opts)
+			require.NoError(t, err)
+
+			requireSameEngines(t, fixed, ac, lines, patterns, opts)
+		}
+	}
+}
+
+func TestDefaultEngine(t *testing.T) {
+	many := make([]string, ahoCorasickMinPatterns)
+	tests := []struct {
+		name     string
+		patterns []string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{name: "regexp", patterns: many, expected: EngineRE2},
+		{name: "few fixed strings", patterns: []string{"a", "b"}, opts: domain.GrepOptions{FixedStrings: true}, expected: EngineFixed},
+		{name: "many fixed strings", patterns: many, opts: domain.GrepOptions{FixedStrings: true}, expected: EngineAhoCorasick},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, defaultEngine(tt.patterns, tt.opts))
+		})
+	}
+}
+
+// benchmarkFixedInput возвращает n паттернов-слов и текст, в котором совпадает примерно каждая десятая строка
+func benchmarkFixedInput(n int) ([]string, []string) {
+	rnd := rand.New(rand.NewPCG(3, 4))
+	word := func() string {
+		b := make([]byte, 6+rnd.IntN(6))
+		for i := range b {
+			b[i] = byte('a' + rnd.IntN(26))
+		}
+		return string(b)
+	}
+	patterns := make([]string, n)
+	for i := range patterns {
+		patterns[i] = word()
+	}
+	lines := make([]string, 1000)
+	for i := range lines {
+		words := make([]string, 12)
+		for j := range words {
+			words[j] = word()
+		}
+		if i%10 == 0 {
+			words[rnd.IntN(len(words))] = patterns[rnd.IntN(n)]
+		}
+		lines[i] = strings.Join(words, " ")
+	}
+	return patterns, lines
+}
+
+func BenchmarkFixedStrings(b *testing.B) {
+	engines := []struct {
+		name    string
+		factory EngineFactory
+	}{
+		{name: EngineFixed, factory: newFixedEngine},
+		{name: EngineAhoCorasick, factory: newAhoCorasickEngine},
+	}
+	for _, n := range []int{1, 8, 100, 1000} {
+		patterns, lines := benchmarkFixedInput(n)
+		size := 0
+		for _, line := range lines {
+			size += len(line) + 1
+		}
+		for _, eng := range engines {
+			for _, opts := range []domain.GrepOptions{{FixedStrings: true}, {FixedStrings: true, IgnoreCase: true}} {
+				b.Run(fmt.Sprintf("%s/patterns=%d/i=%t", eng.name, n, opts.IgnoreCase), func(b *testing.B) {
+					e, err := eng.factory(patterns, opts)
+					require.NoError(b, err)
+					b.SetBytes(int64(size))
+					for b.Loop() {
+						for _, line := range lines {
+							e.Match(line)
+						}
+					}
+				})
+			}
+		}
+	}
+}
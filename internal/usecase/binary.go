@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+	"unix_grep_lite/internal/domain"
+)
+
+// binaryBlockSize размер начального блока входа, по которому он определяется как бинарный
+const binaryBlockSize = 32 * 1024
+
+// isBinary проверяет, содержит ли начальный блок входа NUL-байт или некорректный UTF-8.
+// Если блок не является концом входа (truncated), последняя руна может быть обрезана его границей.
+func isBinary(block string, truncated bool) bool {
+	if strings.IndexByte(block, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := len(block) - 1; i >= 0 && i >= len(block)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(block[i]) {
+				if !utf8.FullRuneInString(block[i:]) {
+					block = block[:i]
+				}
+				break
+			}
+		}
+	}
+	return !utf8.ValidString(block)
+}
+
+// detectBinary сообщает, является ли вход бинарным, по блоку, полученному первым чтением r
+// (не более binaryBlockSize байт), чтобы не читать вход дальше, чем требуется режиму поиска.
+// Возвращаемый reader отдаёт вход целиком, включая прочитанный блок.
+func (m *Matcher) detectBinary(r io.Reader) (io.Reader, bool, error) {
+	if m.opts.BinaryFiles == domain.BinaryFilesText {
+		return r, false, nil
+	}
+	br := bufio.NewReaderSize(r, binaryBlockSize)
+	if _, err := br.Peek(1); err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		return br, false, err
+	}
+	block, _ := br.Peek(br.Buffered()) // уже буферизованные байты читаются без ошибок
+	return br, isBinary(string(block), true), nil
+}
+
+// binaryMatches проверяет наличие выбранной строки в бинарном входе r и выводит
+// вместо строк сообщение о совпадении, как GNU grep (режим --binary-files=binary)
+func (m *Matcher) binaryMatches(r io.Reader, f Formatter) (bool, error) {
+	found, err := m.hasSelectedLine(r)
+	if err != nil || !found {
+		return false, err
+	}
+	return true, f.BinaryMatches()
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name      string
+		block     string
+		truncated bool
+		expected  bool
+	}{
+		{name: "text", block: "hello\nworld\n", expected: false},
+		{name: "utf-8 text", block: "привет\n", expected: false},
+		{name: "NUL byte", block: "foo\x00bar", expected: true},
+		{name: "invalid utf-8", block: "foo\xffbar", expected: true},
+		{name: "rune cut by block boundary", block: "при\xd0", truncated: true, expected: false},
+		{name: "cut rune at end of input", block: "при\xd0", truncated: false, expected: true},
+		{name: "invalid byte before block boundary", block: "\xff\xd0", truncated: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, isBinary(tt.block, tt.truncated))
+		})
+	}
+}
+
+func TestBinaryFiles(t *testing.T) {
+	const input = "foo\x00bar\nbaz\nfoo again\n"
+
+	tests := []struct {
+		name     string
+		input    string
+		pattern  string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "binary file matches",
+			input:    input,
+			pattern:  "foo",
+			opts:     domain.GrepOptions{},
+			expected: "Binary file a.bin matches\n",
+		},
+		{
+			name:     "binary message with only matching",
+			input:    input,
+			pattern:  "foo",
+			opts:     domain.GrepOptions{OnlyMatching: true, LineNumber: true},
+			expected: "Binary file a.bin matches\n",
+		},
+		{
+			name:     "binary file without match",
+			input:    input,
+			pattern:  "qux",
+			opts:     domain.GrepOptions{},
+			expected: "",
+		},
+		{
+			name:     "invalid utf-8 is binary",
+			input:    "foo\xff\n",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{},
+			expected: "Binary file a.bin matches\n",
+		},
+		{
+			name:     "count in binary file",
+			input:    input,
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Count: true},
+			expected: "2\n",
+		},
+		{
+			name:     "text mode prints lines",
+			input:    input,
+			pattern:  "baz",
+			opts:     domain.GrepOptions{BinaryFiles: domain.BinaryFilesText},
+			expected: "baz\n",
+		},
+		{
+			name:     "without-match suppresses matches",
+			input:    input,
+			pattern:  "foo",
+			opts:     domain.GrepOptions{BinaryFiles: domain.BinaryFilesWithoutMatch},
+			expected: "",
+		},
+		{
+			name:     "without-match counts zero",
+			input:    input,
+			pattern:  "foo",
+			opts:     domain.GrepOptions{BinaryFiles: domain.BinaryFilesWithoutMatch, Count: true},
+			expected: "0\n",
+		},
+		{
+			name:     "without-match lists file with -L",
+			input:    input,
+			pattern:  "foo",
+			opts:     domain.GrepOptions{BinaryFiles: domain.BinaryFilesWithoutMatch, FilesWithoutMatch: true},
+			expected: "a.bin\n",
+		},
+		{
+			name:     "without-match keeps text files",
+			input:    "foo\n",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{BinaryFiles: domain.BinaryFilesWithoutMatch},
+			expected: "foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err)
+
+			var sb strings.Builder
+			require.NoError(t, m.SearchFile("a.bin", strings.NewReader(tt.input), &sb))
+			require.Equal(t, tt.expected, sb.String())
+		})
+	}
+}
+
+func TestBinaryFilesSearchMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "binary file matches",
+			input:    "foo\x00bar\nfoo",
+			opts:     domain.GrepOptions{},
+			expected: "Binary file (standard input) matches",
+		},
+		{
+			name:     "without-match",
+			input:    "foo\x00bar\nfoo",
+			opts:     domain.GrepOptions{BinaryFiles: domain.BinaryFilesWithoutMatch},
+			expected: "",
+		},
+		{
+			name:     "text mode",
+			input:    "foo\x00bar\nbaz",
+			opts:     domain.GrepOptions{BinaryFiles: domain.BinaryFilesText},
+			expected: "foo\x00bar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcher("foo", tt.opts)
+			require.NoError(t, err)
+
+			result, err := m.SearchMatch("foo", tt.input, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
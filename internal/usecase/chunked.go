@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"runtime"
+	"sync"
+	"unix_grep_lite/internal/domain"
+	"unsafe"
+)
+
+// defaultChunkSize размер части входа при параллельном поиске; входы не больше одной части
+// ищутся последовательно
+const defaultChunkSize = 8 * 1024 * 1024
+
+// boundaryBlockSize размер блока, читаемого при поиске границ строк вокруг частей
+const boundaryBlockSize = 4 * 1024
+
+// chunkedInput вход с произвольным доступом, который можно искать по частям
+type chunkedInput struct {
+	ra   io.ReaderAt
+	base int64 // смещение начала входа (текущая позиция файла)
+	size int64 // размер входа от base
+}
+
+// defaultChunkMemory ограничение памяти под части входа, одновременно находящиеся в обработке
+const defaultChunkMemory = 64 * 1024 * 1024
+
+// maxPendingResults наибольший объём строк результата части, накапливаемых до её очереди на вывод
+const maxPendingResults = 256 * 1024
+
+// errChunksStopped ошибка поиска в части, результаты которой больше не нужны
+var errChunksStopped = errors.New("chunked search stopped")
+
+// errChunksBusy бюджет частей занят другими входами; вход следует искать последовательно
+var errChunksBusy = errors.New("chunk budget is busy")
+
+// chunkResult итог поиска по одной части входа
+type chunkResult struct {
+	lines int // число строк в части
+	count int // число выбранных строк
+	err   error
+}
+
+// chunkOutput строки результата одной части. Пока не дошла очередь части, они накапливаются
+// в объёме не больше maxPendingResults, после чего поиск в части ждёт очереди; затем строки
+// передаются emit сразу.
+type chunkOutput struct {
+	mu      sync.Mutex
+	cond    sync.Cond
+	pending []domain.Match
+	size    int                      // примерный объём pending в байтах
+	emit    func(domain.Match) error // nil, пока не дошла очередь части
+	err     error                    // первая ошибка emit
+	discard bool                     // поиск прерван, результаты не нужны
+}
+
+func newChunkOutput() *chunkOutput {
+	o := &chunkOutput{}
+	o.cond.L = &o.mu
+	return o
+}
+
+// add передаёт строку результата emit или накапливает её до очереди части
+func (o *chunkOutput) add(match domain.Match) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	size := int(unsafe.Sizeof(match)) + len(match.Text) + len(match.Submatches)*int(unsafe.Sizeof(domain.Span{}))
+	for o.emit == nil && !o.discard && len(o.pending) > 0 && o.size+size > maxPendingResults {
+		o.cond.Wait()
+	}
+	switch {
+	case o.discard:
+		return errChunksStopped
+	case o.err != nil:
+		return o.err
+	case o.emit != nil:
+		o.err = o.emit(match)
+		return o.err
+	}
+	o.pending = append(o.pending, match)
+	o.size += size
+	return nil
+}
+
+// start передаёт emit накопленные строки, после чего следующие строки передаются emit сразу
+func (o *chunkOutput) start(emit func(domain.Match) error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, match := range o.pending {
+		if o.err = emit(match); o.err != nil {
+			break
+		}
+	}
+	o.pending, o.size = nil, 0
+	o.emit = emit
+	o.cond.Broadcast()
+	return o.err
+}
+
+// stop отбрасывает накопленные и следующие строки результата
+func (o *chunkOutput) stop() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pending, o.size = nil, 0
+	o.discard = true
+	o.cond.Broadcast()
+}
+
+// chunkPool буферы частей входа
+var chunkPool sync.Pool
+
+// parallelInput проверяет, можно ли искать r параллельно по частям: r должен поддерживать
+// произвольный доступ (обычный файл, strings.Reader, bytes.Reader) и быть больше одной части,
+// а результат не должен зависеть от порядка просмотра (без -m)
+func (m *Matcher) parallelInput(r io.Reader) (chunkedInput, bool) {
+	ra, ok := r.(interface {
+		io.ReaderAt
+		io.Seeker
+	})
+	if !ok || m.opts.MaxCount || m.opts.SearchZip {
+		return chunkedInput{}, false
+	}
+	var size int64
+	switch r := r.(type) {
+	case interface{ Stat() (fs.FileInfo, error) }:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return chunkedInput{}, false
+		}
+		size = info.Size()
+	case interface{ Size() int64 }:
+		size = r.Size()
+	default:
+		return chunkedInput{}, false
+	}
+	base, err := ra.Seek(0, io.SeekCurrent)
+	if err != nil || size-base <= m.chunkSize {
+		return chunkedInput{}, false
+	}
+	return chunkedInput{ra: ra, base: base, size: size - base}, true
+}
+
+// countChunks параллельно подсчитывает выбранные строки входа (флаг -c)
+func (m *Matcher) countChunks(in chunkedInput) (int, error) {
+	total := 0
+	err := m.forEachChunk(in, 0, 0, func(data []byte, _, _ int, _ int64, _ *chunkOutput) chunkResult {
+		cnt, err := m.countReader(bytes.NewReader(data))
+		return chunkResult{count: cnt, err: err}
+	}, nil, func(res chunkResult) {
+		total += res.count
+	})
+	return total, err
+}
+
+// selectChunks параллельно ищет выбранные строки входа, а при context - и строки контекста
+// -A/-B/-C, и передаёт их emit в порядке входа. Результат совпадает с последовательным поиском:
+// часть просматривается вместе с соседними строками, которые могут сделать её строки контекстом.
+// Возвращает число выбранных строк.
+func (m *Matcher) selectChunks(in chunkedInput, spans, context bool, emit func(domain.Match) error) (int, error) {
+	beforeN, afterN := 0, 0
+	if context {
+		var err error
+		if beforeN, afterN, err = m.contextLengths(); err != nil {
+			return 0, err
+		}
+	}
+
+	total := 0
+	// Строки части становятся контекстом -A для совпадений в afterN строках перед ней
+	// и контекстом -B для совпадений в beforeN строках после неё
+	err := m.forEachChunk(in, afterN, beforeN, func(data []byte, start, end int, from int64, out *chunkOutput) chunkResult {
+		var res chunkResult
+		// Номера строк отсчитываются от начала части, смещения - от начала входа
+		pre := bytes.Count(data[:start], []byte{'\n'})
+		// Из соседних строк в результат попадают только строки самой части
+		collect := func(match domain.Match) error {
+			if off := int(match.Offset); off < start || off >= end {
+				return nil
+			}
+			if match.Kind == domain.KindMatch {
+				res.count++
+			}
+			match.LineNumber -= pre
+			match.Offset += from
+			return out.add(match)
+		}
+		if context {
+			_, res.err = m.selectWithContext(bytes.NewReader(data), spans, collect)
+		} else {
+			_, res.err = m.selectWithoutContext(bytes.NewReader(data), spans, collect)
+		}
+		res.lines = bytes.Count(data[start:end], []byte{'\n'})
+		return res
+	}, emit, func(res chunkResult) {
+		total += res.count
+	})
+	return total, err
+}
+
+// chunkWork ищет в части data[start:end], передавая строки результата out; data содержит
+// также соседние строки части и начинается со смещения from от начала входа
+type chunkWork func(data []byte, start, end int, from int64, out *chunkOutput) chunkResult
+
+// chunksInFlight возвращает число частей, одновременно находящихся в обработке: по одной
+// на процессор и ещё одна, результаты которой выводятся, но не больше, чем помещается в memory
+func chunksInFlight(size, memory int64) int {
+	return max(1, min(runtime.GOMAXPROCS(0)+1, int(memory/size)))
+}
+
+// takeChunkSlots занимает без ожидания свободные места бюджета частей и возвращает их число.
+// Места не ожидаются, чтобы вход, ждущий очереди вывода, не мог заблокировать выводимый вход.
+func (m *Matcher) takeChunkSlots() int {
+	n := 0
+	for n < cap(m.chunkSlots) {
+		select {
+		case m.chunkSlots <- struct{}{}:
+			n++
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// releaseChunkSlots возвращает в бюджет n мест
+func (m *Matcher) releaseChunkSlots(n int) {
+	for range n {
+		<-m.chunkSlots
+	}
+}
+
+// forEachChunk делит вход на части по границам строк и обрабатывает их work параллельно.
+// Строки результата передаются emit в порядке частей с номерами строк от начала входа,
+// итоги частей - consume. work получает также before строк перед частью и after строк после неё.
+// Память ограничена: в обработке находится не больше частей, чем мест в общем для Matcher
+// бюджете chunkSlots, и у каждой, кроме выводимой, накапливается не больше maxPendingResults
+// байт строк результата. Если свободных мест меньше двух, возвращается errChunksBusy.
+func (m *Matcher) forEachChunk(in chunkedInput, before, after int, work chunkWork, emit func(domain.Match) error, consume func(chunkResult)) error {
+	// Бюджет общий для входов, которые ищутся одновременно (SearchFiles с -j, параллельные вызовы);
+	// с одним местом параллельного поиска не получится, и вход выгоднее читать последовательно
+	slots := m.takeChunkSlots()
+	defer m.releaseChunkSlots(slots)
+	if slots < 2 {
+		return errChunksBusy
+	}
+
+	type chunk struct {
+		out *chunkOutput
+		res chan chunkResult
+	}
+	// Поиск в части начинается после её постановки в очередь: в обработке находятся
+	// части в очереди и выводимая часть
+	futures := make(chan chunk, slots-1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(futures)
+		for start := int64(0); start < in.size; {
+			c := chunk{out: newChunkOutput(), res: make(chan chunkResult, 1)}
+			end, err := in.nextLineStart(min(start+m.chunkSize, in.size))
+			select {
+			case futures <- c:
+			case <-done:
+				return
+			}
+			if err != nil {
+				c.res <- chunkResult{err: err}
+				return
+			}
+			go func(start, end int64) {
+				c.res <- in.search(start, end, before, after, c.out, work)
+			}(start, end)
+			start = end
+		}
+	}()
+
+	line := 0
+	for c := range futures {
+		var err error
+		if emit != nil {
+			base := line
+			err = c.out.start(func(match domain.Match) error {
+				match.LineNumber += base
+				return emit(match)
+			})
+		}
+		res := <-c.res
+		if err == nil {
+			err = res.err
+		}
+		if err != nil {
+			// Оставшиеся части прерываются, не дожидаясь очереди
+			close(done)
+			for c := range futures {
+				c.out.stop()
+				<-c.res // место в бюджете освобождается после окончания поиска в части
+			}
+			return err
+		}
+		consume(res)
+		line += res.lines
+	}
+	close(done)
+	return nil
+}
+
+// search читает часть [start, end) вместе с before строками перед ней и after строками
+// после и выполняет по ней поиск work
+func (in chunkedInput) search(start, end int64, before, after int, out *chunkOutput, work chunkWork) chunkResult {
+	from, err := in.lineStartBefore(start, before)
+	if err != nil {
+		return chunkResult{err: err}
+	}
+	to := end
+	for i := 0; i < after && to < in.size; i++ {
+		if to, err = in.nextLineStart(to + 1); err != nil {
+			return chunkResult{err: err}
+		}
+	}
+
+	buf, _ := chunkPool.Get().(*[]byte)
+	if buf == nil {
+		buf = new([]byte)
+	}
+	defer chunkPool.Put(buf)
+	if int64(cap(*buf)) < to-from {
+		*buf = make([]byte, to-from)
+	}
+	data := (*buf)[:to-from]
+	if _, err := in.ra.ReadAt(data, in.base+from); err != nil && err != io.EOF {
+		return chunkResult{err: err}
+	}
+	return work(data, int(start-from), int(end-from), from, out)
+}
+
+// nextLineStart возвращает ближайшее к pos начало строки не раньше pos или размер входа
+func (in chunkedInput) nextLineStart(pos int64) (int64, error) {
+	if pos >= in.size {
+		return in.size, nil
+	}
+	if pos > 0 {
+		pos-- // перевод строки непосредственно перед pos
+	}
+	block := make([]byte, boundaryBlockSize)
+	for pos < in.size {
+		n, err := in.ra.ReadAt(block[:min(int64(len(block)), in.size-pos)], in.base+pos)
+		if i := bytes.IndexByte(block[:n], '\n'); i >= 0 {
+			return pos + int64(i) + 1, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if n == 0 {
+			break
+		}
+		pos += int64(n)
+	}
+	return in.size, nil
+}
+
+// lineStartBefore возвращает начало n-й строки перед строкой, начинающейся в pos, или 0
+func (in chunkedInput) lineStartBefore(pos int64, n int) (int64, error) {
+	if n == 0 || pos == 0 {
+		return pos, nil
+	}
+	block := make([]byte, boundaryBlockSize)
+	// Перевод строки в pos-1 завершает предыдущую строку и не считается
+	end := pos - 1
+	for end > 0 {
+		from := max(0, end-int64(len(block)))
+		data := block[:end-from]
+		if _, err := in.ra.ReadAt(data, in.base+from); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(data) - 1; i >= 0; i-- {
+			if data[i] == '\n' {
+				if n--; n == 0 {
+					return from + int64(i) + 1, nil
+				}
+			}
+		}
+		end = from
+	}
+	return 0, nil
+}
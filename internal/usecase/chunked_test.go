@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// chunkedContent текст со строками разной длины, в том числе длиннее части, пустыми строками
+// и совпадениями, расположенными плотно и редко
+func chunkedContent() string {
+	var sb strings.Builder
+	for i := range 400 {
+		switch {
+		case i%97 == 0:
+			sb.WriteString(strings.Repeat("long line ", 20) + "match\n")
+		case i%13 == 0, i%13 == 1:
+			fmt.Fprintf(&sb, "match %d\n", i)
+		case i%11 == 0:
+			sb.WriteString("\n")
+		default:
+			fmt.Fprintf(&sb, "line %d\n", i)
+		}
+	}
+	sb.WriteString("last match") // без завершающего перевода строки
+	return sb.String()
+}
+
+func TestSearchChunksAgreesWithSequential(t *testing.T) {
+	t.Parallel()
+
+	content := chunkedContent()
+	path := filepath.Join(t.TempDir(), "big.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	optsSet := []domain.GrepOptions{
+		{},
+		{LineNumber: true, ByteOffset: true},
+		{InvertMatch: true, LineNumber: true},
+		{Count: true},
+		{Count: true, InvertMatch: true},
+		{OnlyMatching: true, LineNumber: true, ByteOffset: true},
+		{AfterContext: true, NumAfter: 2, LineNumber: true},
+		{BeforeContext: true, NumBefore: 3, LineNumber: true},
+		{AroundContext: true, NumAround: 1, LineNumber: true, ByteOffset: true},
+		{AroundContext: true, NumAround: 5, InvertMatch: true, LineNumber: true},
+		{AfterContext: true, NumAfter: 1, BeforeContext: true, NumBefore: 4},
+		{JSON: true, AroundContext: true, NumAround: 2},
+	}
+
+	for _, pattern := range []string{"match", "^match [0-9]+$", "line 1"} {
+		for _, opts := range optsSet {
+			for _, chunkSize := range []int64{1, 16, 100, 1000} {
+				msg := fmt.Sprintf("pattern=%q opts=%+v chunk=%d", pattern, opts, chunkSize)
+				m, err := NewMatcher(pattern, opts)
+				require.NoError(t, err, msg)
+				m.chunkSize = chunkSize
+
+				// io.MultiReader скрывает произвольный доступ, и поиск выполняется последовательно
+				var want strings.Builder
+				require.NoError(t, m.SearchFile(path, io.MultiReader(strings.NewReader(content)), &want), msg)
+
+				f, err := os.Open(path)
+				require.NoError(t, err)
+				_, parallel := m.parallelInput(f)
+				require.True(t, parallel, msg)
+
+				var got strings.Builder
+				require.NoError(t, m.SearchFile(path, f, &got), msg)
+				require.NoError(t, f.Close())
+				require.Equal(t, want.String(), got.String(), msg)
+			}
+		}
+	}
+}
+
+func TestSearchChunksFromOffset(t *testing.T) {
+	t.Parallel()
+
+	content := chunkedContent()
+	path := filepath.Join(t.TempDir(), "big.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	m, err := NewMatcher("match", domain.GrepOptions{LineNumber: true, ByteOffset: true, AroundContext: true, NumAround: 1})
+	require.NoError(t, err)
+	m.chunkSize = 64
+
+	// Вход начинается с текущей позиции файла, как у перенаправленного stdin
+	const skip = 1000
+	var want strings.Builder
+	require.NoError(t, m.Search(io.MultiReader(strings.NewReader(content[skip:])), &want))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.Seek(skip, io.SeekStart)
+	require.NoError(t, err)
+
+	var got strings.Builder
+	require.NoError(t, m.Search(f, &got))
+	require.Equal(t, want.String(), got.String())
+}
+
+func TestParallelInput(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "big.txt")
+	require.NoError(t, os.WriteFile(path, []byte(chunkedContent()), 0o644))
+
+	tests := []struct {
+		name      string
+		opts      domain.GrepOptions
+		chunkSize int64
+		expected  bool
+	}{
+		{name: "large file", chunkSize: 64, expected: true},
+		{name: "single chunk", chunkSize: defaultChunkSize, expected: false},
+		{name: "max count", opts: domain.GrepOptions{MaxCount: true, NumMax: 1}, chunkSize: 64, expected: false},
+		{name: "search zip", opts: domain.GrepOptions{SearchZip: true}, chunkSize: 64, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcher("match", tt.opts)
+			require.NoError(t, err)
+			m.chunkSize = tt.chunkSize
+
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+			_, ok := m.parallelInput(f)
+			require.Equal(t, tt.expected, ok)
+		})
+	}
+
+	m, err := NewMatcher("match", domain.GrepOptions{})
+	require.NoError(t, err)
+	m.chunkSize = 64
+	_, ok := m.parallelInput(strings.NewReader(chunkedContent()))
+	require.True(t, ok, "in-memory input")
+	_, ok = m.parallelInput(io.MultiReader(strings.NewReader(chunkedContent())))
+	require.False(t, ok, "input without random access")
+}
+
+func TestSearchMatchChunks(t *testing.T) {
+	t.Parallel()
+
+	content := chunkedContent()
+	for _, opts := range []domain.GrepOptions{
+		{LineNumber: true},
+		{Count: true},
+		{AroundContext: true, NumAround: 2, LineNumber: true},
+	} {
+		m, err := NewMatcher("match", opts)
+		require.NoError(t, err)
+		want, err := m.SearchMatch("match", content, opts)
+		require.NoError(t, err)
+
+		m.chunkSize = 50
+		got, err := m.SearchMatch("match", content, opts)
+		require.NoError(t, err)
+		require.Equal(t, want, got, "opts=%+v", opts)
+	}
+}
+
+// chunkReadCounter вход в памяти, считающий чтения частей (блоков больше boundaryBlockSize)
+type chunkReadCounter struct {
+	*strings.Reader
+	reads atomic.Int64
+}
+
+func (r *chunkReadCounter) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) > boundaryBlockSize {
+		r.reads.Add(1)
+	}
+	return r.Reader.ReadAt(p, off)
+}
+
+func TestSearchChunksMemoryBound(t *testing.T) {
+	t.Parallel()
+
+	// Выбираются все строки (-v), а вывод остановлен на первой записи
+	content := strings.Repeat("line of the input\n", 100000)
+	m, err := NewMatcher("zzz", domain.GrepOptions{InvertMatch: true})
+	require.NoError(t, err)
+	m.chunkSize = 16 * 1024
+	m.chunkSlots = make(chan struct{}, chunksInFlight(m.chunkSize, 3*m.chunkSize))
+
+	r := &chunkReadCounter{Reader: strings.NewReader(content)}
+	_, parallel := m.parallelInput(r)
+	require.True(t, parallel)
+
+	w := &firstWriteWriter{first: make(chan int), release: make(chan struct{})}
+	errc := make(chan error)
+	go func() { errc <- m.Search(r, w) }()
+	<-w.first
+
+	// Пока вывод стоит, в обработку попадает не больше частей, чем помещается в chunkMemory,
+	// независимо от числа процессоров
+	time.Sleep(100 * time.Millisecond)
+	require.LessOrEqual(t, r.reads.Load(), int64(3))
+
+	close(w.release)
+	require.NoError(t, <-errc)
+}
+
+func TestSearchChunksSharedBudget(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("line of the input\n", 100000)
+	m, err := NewMatcher("zzz", domain.GrepOptions{InvertMatch: true})
+	require.NoError(t, err)
+	m.chunkSize = 16 * 1024
+	m.chunkSlots = make(chan struct{}, chunksInFlight(m.chunkSize, 3*m.chunkSize))
+
+	// Два входа ищутся одновременно, вывод обоих остановлен на первой записи
+	inputs := make([]*chunkReadCounter, 2)
+	writers := make([]*firstWriteWriter, len(inputs))
+	errc := make(chan error, len(inputs))
+	for i := range inputs {
+		inputs[i] = &chunkReadCounter{Reader: strings.NewReader(content)}
+		writers[i] = &firstWriteWriter{first: make(chan int), release: make(chan struct{})}
+		go func() { errc <- m.Search(inputs[i], writers[i]) }()
+	}
+	for _, w := range writers {
+		<-w.first
+	}
+
+	// Бюджет частей общий: второй вход не добавляет своих частей к частям первого
+	time.Sleep(100 * time.Millisecond)
+	require.LessOrEqual(t, inputs[0].reads.Load()+inputs[1].reads.Load(), int64(3))
+
+	for _, w := range writers {
+		close(w.release)
+	}
+	for range inputs {
+		require.NoError(t, <-errc)
+	}
+	require.Empty(t, m.chunkSlots, "slots are released")
+}
+
+func TestChunkOutputPendingLimit(t *testing.T) {
+	t.Parallel()
+
+	o := newChunkOutput()
+	match := domain.Match{Text: strings.Repeat("x", maxPendingResults/4)}
+	added := make(chan int)
+	go func() {
+		for i := range 8 {
+			require.NoError(t, o.add(match))
+			added <- i
+		}
+		close(added)
+	}()
+
+	// До очереди части накапливается не больше maxPendingResults, дальше поиск ждёт
+	for i := range 3 {
+		require.Equal(t, i, <-added)
+	}
+	select {
+	case <-added:
+		t.Fatal("pending results exceed limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	var emitted int
+	require.NoError(t, o.start(func(domain.Match) error {
+		emitted++
+		return nil
+	}))
+	for range added {
+	}
+	require.Equal(t, 8, emitted)
+}
+
+func BenchmarkSearchChunks(b *testing.B) {
+	input := strings.Repeat(benchmarkLogInput(), 20)
+	path := filepath.Join(b.TempDir(), "big.log")
+	require.NoError(b, os.WriteFile(path, []byte(input), 0o644))
+
+	for _, bm := range []struct {
+		name      string
+		chunkSize int64
+	}{
+		{name: "sequential", chunkSize: int64(len(input))},
+		{name: "parallel", chunkSize: defaultChunkSize},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			m, err := NewMatcher("[0-9]{4,}$", domain.GrepOptions{})
+			require.NoError(b, err)
+			m.chunkSize = bm.chunkSize
+			b.SetBytes(int64(len(input)))
+			for b.Loop() {
+				f, err := os.Open(path)
+				require.NoError(b, err)
+				require.NoError(b, m.Search(f, io.Discard))
+				require.NoError(b, f.Close())
+			}
+		})
+	}
+}
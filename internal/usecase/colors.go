@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"strings"
+	"unix_grep_lite/internal/domain"
+)
+
+// DefaultColors цвета подсветки по умолчанию, как в GNU grep
+var DefaultColors = domain.Colors{
+	SelectedMatch: "01;31",
+	ContextMatch:  "01;31",
+	FileName:      "35",
+	LineNumber:    "32",
+	Separator:     "36",
+}
+
+// ParseGrepColors разбирает значение переменной окружения GREP_COLORS
+// вида "ms=01;31:mc=01;31:sl=:cx=:fn=35:ln=32:se=36" поверх цветов по умолчанию.
+// Неизвестные и некорректные элементы игнорируются, как и в GNU grep.
+func ParseGrepColors(s string) domain.Colors {
+	colors := DefaultColors
+	for item := range strings.SplitSeq(s, ":") {
+		name, value, hasValue := strings.Cut(item, "=")
+		if hasValue && !isSGR(value) {
+			continue
+		}
+		switch {
+		case name == "mt" && hasValue:
+			colors.SelectedMatch, colors.ContextMatch = value, value
+		case name == "ms" && hasValue:
+			colors.SelectedMatch = value
+		case name == "mc" && hasValue:
+			colors.ContextMatch = value
+		case name == "sl" && hasValue:
+			colors.SelectedLine = value
+		case name == "cx" && hasValue:
+			colors.ContextLine = value
+		case name == "fn" && hasValue:
+			colors.FileName = value
+		case name == "ln" && hasValue:
+			colors.LineNumber = value
+		case name == "se" && hasValue:
+			colors.Separator = value
+		case name == "rv" && !hasValue:
+			colors.Reverse = true
+		case name == "ne" && !hasValue:
+			colors.NoEraseLine = true
+		}
+	}
+	return colors
+}
+
+// isSGR проверяет, что значение состоит только из цифр и ';', как параметры SGR
+func isSGR(value string) bool {
+	for _, r := range value {
+		if (r < '0' || r > '9') && r != ';' {
+			return false
+		}
+	}
+	return true
+}
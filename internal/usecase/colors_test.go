@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGrepColors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected domain.Colors
+	}{
+		{
+			name:     "empty uses defaults",
+			input:    "",
+			expected: DefaultColors,
+		},
+		{
+			name:  "override capabilities",
+			input: "ms=04:mc=05:sl=1:cx=2:fn=34:ln=33:se=35",
+			expected: domain.Colors{
+				SelectedMatch: "04",
+				ContextMatch:  "05",
+				SelectedLine:  "1",
+				ContextLine:   "2",
+				FileName:      "34",
+				LineNumber:    "33",
+				Separator:     "35",
+			},
+		},
+		{
+			name:  "mt sets both match colors",
+			input: "mt=01;32",
+			expected: domain.Colors{
+				SelectedMatch: "01;32",
+				ContextMatch:  "01;32",
+				FileName:      "35",
+				LineNumber:    "32",
+				Separator:     "36",
+			},
+		},
+		{
+			name:  "boolean capabilities",
+			input: "rv:ne",
+			expected: domain.Colors{
+				SelectedMatch: "01;31",
+				ContextMatch:  "01;31",
+				FileName:      "35",
+				LineNumber:    "32",
+				Separator:     "36",
+				Reverse:       true,
+				NoEraseLine:   true,
+			},
+		},
+		{
+			name:  "empty value disables color",
+			input: "fn=",
+			expected: domain.Colors{
+				SelectedMatch: "01;31",
+				ContextMatch:  "01;31",
+				LineNumber:    "32",
+				Separator:     "36",
+			},
+		},
+		{
+			name:     "invalid items are ignored",
+			input:    "ms=red:xx=1:fn",
+			expected: DefaultColors,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, ParseGrepColors(tt.input))
+		})
+	}
+}
+
+func TestColoredOutput(t *testing.T) {
+	const (
+		red   = "\033[01;31m\033[K"
+		green = "\033[32m\033[K"
+		cyan  = "\033[36m\033[K"
+		mag   = "\033[35m\033[K"
+		end   = "\033[m\033[K"
+	)
+
+	tests := []struct {
+		name     string
+		input    string
+		pattern  string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "color disabled",
+			input:    "foo bar",
+			pattern:  "bar",
+			opts:     domain.GrepOptions{Colors: DefaultColors},
+			expected: "foo bar\n",
+		},
+		{
+			name:     "highlight every match",
+			input:    "a foo b foo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Color: true, Colors: DefaultColors},
+			expected: "a " + red + "foo" + end + " b " + red + "foo" + end + "\n",
+		},
+		{
+			name:     "file name and line number",
+			input:    "foo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Color: true, Colors: DefaultColors, WithFilename: true, LineNumber: true},
+			expected: mag + "a.txt" + end + cyan + ":" + end + green + "1" + end + cyan + ":" + end + red + "foo" + end + "\n",
+		},
+		{
+			name:     "context separator",
+			input:    "foo\nx\ny\nfoo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Color: true, Colors: DefaultColors, AfterContext: true},
+			expected: red + "foo" + end + "\n" + cyan + "--" + end + "\n" + red + "foo" + end + "\n",
+		},
+		{
+			name:     "invert highlights matches in context lines",
+			input:    "foo\nbar",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Color: true, Colors: DefaultColors, InvertMatch: true, BeforeContext: true, NumBefore: 1},
+			expected: red + "foo" + end + "\nbar\n",
+		},
+		{
+			name:     "only matching",
+			input:    "foo bar",
+			pattern:  "ba.",
+			opts:     domain.GrepOptions{Color: true, Colors: DefaultColors, OnlyMatching: true},
+			expected: red + "bar" + end + "\n",
+		},
+		{
+			name:     "count",
+			input:    "foo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Color: true, Colors: DefaultColors, Count: true, WithFilename: true},
+			expected: mag + "a.txt" + end + cyan + ":" + end + "1\n",
+		},
+		{
+			name:     "selected line color and no erase line",
+			input:    "a foo b",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Color: true, Colors: ParseGrepColors("sl=1:ne")},
+			expected: "\033[1ma \033[m\033[01;31mfoo\033[m\033[1m b\033[m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			require.NoError(t, matcher.SearchFile("a.txt", strings.NewReader(tt.input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
@@ -1,26 +1,31 @@
 package usecase
 
 import (
+	"io"
 	"strings"
 )
 
 // countOfMatching подсчитывает количество совпавших строк (флаг -c)
 func (m *Matcher) countOfMatching(input string) int {
-	if input == "" {
-		return 0
+	r := strings.NewReader(input)
+	if chunked, ok := m.parallelInput(r); ok {
+		if cnt, err := m.countChunks(chunked); err == nil { // strings.Reader не возвращает ошибок
+			return cnt
+		}
 	}
+	cnt, _ := m.countReader(r)
+	return cnt
+}
 
-	cnt := 0
-	lines := strings.SplitSeq(input, "\n") // итератор по строкам
-	for line := range lines {
-		isMatch := m.lineIsMatch(line)
-		// Инверсия для флага -v
-		if m.opts.InvertMatch {
-			isMatch = !isMatch
-		}
-		if isMatch {
+// countReader потоково подсчитывает количество совпавших строк из r.
+// При флаге -m чтение прекращается, как только счётчик достигает NumMax.
+func (m *Matcher) countReader(r io.Reader) (int, error) {
+	cnt, limit := 0, m.maxCount()
+	sc := m.scanLines(r)
+	for cnt != limit && m.scanSelected(sc, 0) {
+		if m.lineIsSelected(sc.Line()) {
 			cnt++
 		}
 	}
-	return cnt
+	return cnt, sc.Err()
 }
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Сигнатуры сжатых форматов в начале входа
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh") // за ней следуют уровень сжатия '1'..'9' и сигнатура блока
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+	bzip2Block = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59} // первый блок сжатых данных
+	bzip2End   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90} // конец потока без блоков (пустой файл)
+)
+
+// bzip2HeaderLen длина заголовка bzip2 вместе с сигнатурой первого блока
+const bzip2HeaderLen = 10
+
+// decompress распознаёт сжатый вход по сигнатуре и возвращает reader распакованных данных
+// (флаг -z). Несжатый вход возвращается без изменений. Функция close освобождает ресурсы
+// распаковщика и должна быть вызвана после чтения.
+func decompress(r io.Reader) (io.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(bzip2HeaderLen)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, func() { _ = zr.Close() }, nil
+	case isBzip2(magic):
+		return bzip2.NewReader(br), func() {}, nil
+	case bytes.HasPrefix(magic, xzMagic):
+		zr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, func() {}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		// Один файл распаковывается в одной горутине: параллельность даёт пул файлов
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr.Close, nil
+	}
+	return br, func() {}, nil
+}
+
+// isBzip2 проверяет заголовок bzip2. Трёх байт "BZh" недостаточно: с них может начинаться
+// обычный текст, поэтому проверяются также уровень сжатия и сигнатура первого блока.
+func isBzip2(magic []byte) bool {
+	if len(magic) < bzip2HeaderLen || !bytes.HasPrefix(magic, bzip2Magic) || magic[3] < '1' || magic[3] > '9' {
+		return false
+	}
+	sig := magic[len(bzip2Magic)+1 : bzip2HeaderLen]
+	return bytes.Equal(sig, bzip2Block) || bytes.Equal(sig, bzip2End)
+}
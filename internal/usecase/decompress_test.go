@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+const compressedInput = "hello\nERROR disk full\nbye\n"
+
+// bzip2Input compressedInput, сжатый утилитой bzip2 (в стандартной библиотеке нет кодировщика)
+var bzip2Input = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x39, 0xf5, 0xbe, 0x7c, 0x00, 0x00,
+	0x07, 0xd7, 0x80, 0x00, 0x10, 0x40, 0x00, 0x02, 0x00, 0x90, 0x00, 0x17, 0x6c, 0x8a, 0x20, 0x20,
+	0x00, 0x22, 0x8c, 0x9a, 0x3d, 0x43, 0x6a, 0x3d, 0x42, 0x86, 0x9a, 0x60, 0x00, 0x9d, 0x64, 0xc3,
+	0x0b, 0x07, 0x08, 0xb3, 0xcf, 0xb8, 0xa5, 0xa8, 0x92, 0xf8, 0xbb, 0x92, 0x29, 0xc2, 0x84, 0x81,
+	0xcf, 0xad, 0xf3, 0xe0,
+}
+
+// compress сжимает s кодировщиком, созданным newWriter
+func compress(t *testing.T, s string, newWriter func(io.Writer) (io.WriteCloser, error)) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw, err := newWriter(&buf)
+	require.NoError(t, err)
+	_, err = io.WriteString(zw, s)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name: "gzip",
+			input: compress(t, compressedInput, func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			}),
+		},
+		{
+			name:  "bzip2",
+			input: bzip2Input,
+		},
+		{
+			name: "xz",
+			input: compress(t, compressedInput, func(w io.Writer) (io.WriteCloser, error) {
+				return xz.NewWriter(w)
+			}),
+		},
+		{
+			name: "zstd",
+			input: compress(t, compressedInput, func(w io.Writer) (io.WriteCloser, error) {
+				return zstd.NewWriter(w)
+			}),
+		},
+		{
+			name:  "plain text",
+			input: []byte(compressedInput),
+		},
+		{
+			name:  "plain text with bzip2 prefix",
+			input: []byte("BZhello\nERROR disk full\n"),
+		},
+		{
+			name:  "plain text with bzip2 prefix and level",
+			input: []byte("BZh9 1AY&SY\nERROR disk full\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := domain.GrepOptions{SearchZip: true, WithFilename: true, LineNumber: true}
+			m, err := NewMatcher("ERROR", opts)
+			require.NoError(t, err)
+
+			var sb strings.Builder
+			require.NoError(t, m.SearchFile("app.log", bytes.NewReader(tt.input), &sb))
+			require.Equal(t, "app.log:2:ERROR disk full\n", sb.String())
+		})
+	}
+}
+
+func TestDecompressEmptyBzip2(t *testing.T) {
+	t.Parallel()
+
+	// Вывод bzip2 </dev/null: заголовок и сразу конец потока
+	input := []byte{0x42, 0x5a, 0x68, 0x39, 0x17, 0x72, 0x45, 0x38, 0x50, 0x90, 0x00, 0x00, 0x00, 0x00}
+
+	m, err := NewMatcher("", domain.GrepOptions{SearchZip: true, Count: true})
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, m.SearchFile("empty.bz2", bytes.NewReader(input), &sb))
+	require.Equal(t, "0\n", sb.String())
+}
+
+func TestDecompressCorrupted(t *testing.T) {
+	t.Parallel()
+
+	input := compress(t, compressedInput, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+	input = input[:len(input)/2]
+
+	m, err := NewMatcher("ERROR", domain.GrepOptions{SearchZip: true})
+	require.NoError(t, err)
+
+	var fileErr *domain.FileError
+	err = m.SearchFile("app.log.gz", bytes.NewReader(input), io.Discard)
+	require.ErrorAs(t, err, &fileErr)
+	require.Equal(t, "app.log.gz", fileErr.Path)
+}
+
+func TestDecompressDisabled(t *testing.T) {
+	t.Parallel()
+
+	input := compress(t, compressedInput, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+
+	m, err := NewMatcher("ERROR", domain.GrepOptions{})
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, m.SearchFile("app.log.gz", bytes.NewReader(input), &sb))
+	require.NotContains(t, sb.String(), "ERROR")
+}
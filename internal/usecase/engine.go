@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unix_grep_lite/internal/domain"
+)
+
+// MatchEngine алгоритм поиска совпадений набора паттернов в строке.
+// Движок учитывает параметры сопоставления (-F, -i, -w, -x), с которыми создан,
+// и должен быть безопасен для одновременного использования из нескольких горутин.
+type MatchEngine interface {
+	// Match проверяет, есть ли в строке хотя бы одно совпадение
+	Match(line string) bool
+	// Find возвращает границы первого (самого левого) непустого совпадения или nil
+	Find(line string) []int
+	// FindAll возвращает границы непересекающихся непустых совпадений слева направо;
+	// из совпадений с одной позиции выбирается самое длинное
+	FindAll(line string) [][]int
+	// LiteralPrefixes возвращает литералы, с одного из которых начинается любое совпадение,
+	// или nil, если такой набор неизвестен (например, при -i или паттерне вида ".*")
+	LiteralPrefixes() []string
+}
+
+// EngineFactory создаёт движок для набора паттернов с параметрами opts
+type EngineFactory func(patterns []string, opts domain.GrepOptions) (MatchEngine, error)
+
+// Имена встроенных движков
+const (
+	EngineRE2   = "re2"   // регулярные выражения RE2 (пакет regexp)
+	EngineFixed = "fixed" // фиксированные строки
+
+	// EngineAhoCorasick множество фиксированных строк на автомате Ахо-Корасик
+	EngineAhoCorasick = "aho-corasick"
+)
+
+var (
+	enginesMu sync.RWMutex
+	engines   = map[string]EngineFactory{
+		EngineRE2:   newRegexEngine,
+		EngineFixed: newFixedEngine,
+
+		EngineAhoCorasick: newAhoCorasickEngine,
+	}
+)
+
+// RegisterEngine регистрирует движок под именем name для выбора флагом --engine.
+// Движок с тем же именем заменяется. Встроенные движки получают строки, разделяющие
+// память с буфером чтения; зарегистрированному движку передаются их копии,
+// поэтому он может сохранять строки как обычные неизменяемые строки Go.
+func RegisterEngine(name string, factory EngineFactory) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	engines[name] = func(patterns []string, opts domain.GrepOptions) (MatchEngine, error) {
+		e, err := factory(patterns, opts)
+		if err != nil {
+			return nil, err
+		}
+		return clonedLines{e}, nil
+	}
+}
+
+// clonedLines передаёт движку копии строк вместо строк из буфера чтения
+type clonedLines struct {
+	MatchEngine
+}
+
+func (e clonedLines) Match(line string) bool {
+	return e.MatchEngine.Match(strings.Clone(line))
+}
+
+func (e clonedLines) Find(line string) []int {
+	return e.MatchEngine.Find(strings.Clone(line))
+}
+
+func (e clonedLines) FindAll(line string) [][]int {
+	return e.MatchEngine.FindAll(strings.Clone(line))
+}
+
+// Engines возвращает имена зарегистрированных движков в алфавитном порядке
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return slices.Sorted(maps.Keys(engines))
+}
+
+// newEngine создаёт движок, заданный флагом --engine, а без него - выбранный по флагам поиска
+func newEngine(patterns []string, opts domain.GrepOptions) (MatchEngine, error) {
+	name := opts.Engine
+	if name == "" {
+		name = defaultEngine(patterns, opts)
+	}
+
+	enginesMu.RLock()
+	factory, ok := engines[name]
+	enginesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", domain.ErrUnknownEngine, name)
+	}
+	return factory(patterns, opts)
+}
+
+// defaultEngine выбирает движок по флагам поиска и числу паттернов
+func defaultEngine(patterns []string, opts domain.GrepOptions) string {
+	if opts.FixedStrings {
+		if len(patterns) >= ahoCorasickMinPatterns {
+			return EngineAhoCorasick
+		}
+		return EngineFixed
+	}
+	return EngineRE2
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// engineLines строки для сравнения движков
+var engineLines = []string{
+	"",
+	"foo",
+	"foobar foo",
+	"FOO bar",
+	"a.b axb",
+	"foo_bar foo-bar",
+	"привет ПРИВЕТ",
+	"barfoo",
+	"\u212Aelvin \u017Fecret", // KELVIN SIGN и LATIN SMALL LETTER LONG S равны K и S без учёта регистра
+	"STRAẞE straße",
+}
+
+func TestEnginesAgreeOnFixedStrings(t *testing.T) {
+	patterns := [][]string{
+		{"foo"},
+		{"foo", "foobar"},
+		{"a.b"},
+		{"bar", ""},
+		{"привет"},
+		{"kelvin", "secret"},
+		{"straße"},
+	}
+	optsSet := []domain.GrepOptions{
+		{FixedStrings: true},
+		{FixedStrings: true, IgnoreCase: true},
+		{FixedStrings: true, WordRegexp: true},
+		{FixedStrings: true, LineRegexp: true},
+	}
+
+	for _, ps := range patterns {
+		for _, opts := range optsSet {
+			fixed, err := newFixedEngine(ps, opts)
+			require.NoError(t, err)
+			re2, err := newRegexEngine(ps, opts)
+			require.NoError(t, err)
+
+			for _, line := range engineLines {
+				msg := "patterns=%q opts=%+v line=%q"
+				require.Equal(t, re2.Match(line), fixed.Match(line), msg, ps, opts, line)
+				require.Equal(t, re2.Find(line), fixed.Find(line), msg, ps, opts, line)
+				require.Equal(t, normalizeLocs(re2.FindAll(line)), normalizeLocs(fixed.FindAll(line)), msg, ps, opts, line)
+			}
+		}
+	}
+}
+
+// normalizeLocs приводит пустой результат к nil для сравнения
+func normalizeLocs(locs [][]int) [][]int {
+	if len(locs) == 0 {
+		return nil
+	}
+	return locs
+}
+
+func TestRegexEngineFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		opts     domain.GrepOptions
+		line     string
+		expected []int
+	}{
+		{name: "leftmost longest", patterns: []string{"fo", "foo"}, line: "a foo", expected: []int{2, 5}},
+		{name: "skips leading empty match", patterns: []string{"b*"}, line: "abb", expected: []int{1, 3}},
+		{name: "word", patterns: []string{"foo"}, opts: domain.GrepOptions{WordRegexp: true}, line: "foobar foo", expected: []int{7, 10}},
+		{name: "no match", patterns: []string{"x"}, line: "abc", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := newRegexEngine(tt.patterns, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, e.Find(tt.line))
+		})
+	}
+}
+
+func TestLiteralPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		engine   EngineFactory
+		patterns []string
+		opts     domain.GrepOptions
+		expected []string
+	}{
+		{name: "regex prefixes", engine: newRegexEngine, patterns: []string{"ERROR.*", "WARN[0-9]"}, expected: []string{"ERROR", "WARN"}},
+		{name: "regex without prefix", engine: newRegexEngine, patterns: []string{"ERROR", ".*x"}, expected: nil},
+		{name: "regex ignore case", engine: newRegexEngine, patterns: []string{"ERROR"}, opts: domain.GrepOptions{IgnoreCase: true}, expected: nil},
+		{name: "regex fixed strings", engine: newRegexEngine, patterns: []string{"a.b"}, opts: domain.GrepOptions{FixedStrings: true}, expected: []string{"a.b"}},
+		{name: "fixed patterns", engine: newFixedEngine, patterns: []string{"foo", "bar"}, expected: []string{"foo", "bar"}},
+		{name: "fixed empty pattern", engine: newFixedEngine, patterns: []string{"foo", ""}, expected: nil},
+		{name: "fixed ignore case", engine: newFixedEngine, patterns: []string{"foo"}, opts: domain.GrepOptions{IgnoreCase: true}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := tt.engine(tt.patterns, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, e.LiteralPrefixes())
+		})
+	}
+}
+
+// upperEngine тестовый движок: совпадение - строка целиком, если она в верхнем регистре
+type upperEngine struct{}
+
+func (upperEngine) Match(line string) bool {
+	return line != "" && strings.ToUpper(line) == line
+}
+
+func (e upperEngine) Find(line string) []int {
+	if !e.Match(line) {
+		return nil
+	}
+	return []int{0, len(line)}
+}
+
+func (e upperEngine) FindAll(line string) [][]int {
+	if loc := e.Find(line); loc != nil {
+		return [][]int{loc}
+	}
+	return nil
+}
+
+func (upperEngine) LiteralPrefixes() []string {
+	return nil
+}
+
+func TestRegisterEngine(t *testing.T) {
+	t.Parallel()
+
+	RegisterEngine("test-upper", func([]string, domain.GrepOptions) (MatchEngine, error) {
+		return upperEngine{}, nil
+	})
+	require.Contains(t, Engines(), "test-upper")
+
+	m, err := NewMatcher("ignored", domain.GrepOptions{Engine: "test-upper", Count: true})
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, m.Search(strings.NewReader("abc\nABC\nDEF\nx"), &sb))
+	require.Equal(t, "2\n", sb.String())
+}
+
+func TestUnknownEngine(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewMatcher("foo", domain.GrepOptions{Engine: "nope"})
+	require.ErrorIs(t, err, domain.ErrUnknownEngine)
+}
+
+// keepEngine тестовый движок, сохраняющий все проверенные строки
+type keepEngine struct {
+	upperEngine
+	lines *[]string
+}
+
+func (e keepEngine) Match(line string) bool {
+	*e.lines = append(*e.lines, line)
+	return false
+}
+
+func TestRegisterEngineClonesLines(t *testing.T) {
+	t.Parallel()
+
+	var kept []string
+	RegisterEngine("test-keep", func([]string, domain.GrepOptions) (MatchEngine, error) {
+		return keepEngine{lines: &kept}, nil
+	})
+
+	// Вход больше буфера сканера, чтобы буфер перезаписывался во время поиска
+	var input strings.Builder
+	expected := make([]string, 20000)
+	for i := range expected {
+		expected[i] = fmt.Sprintf("line %d", i)
+		input.WriteString(expected[i] + "\n")
+	}
+
+	m, err := NewMatcher("ignored", domain.GrepOptions{Engine: "test-keep"})
+	require.NoError(t, err)
+	require.NoError(t, m.Search(strings.NewReader(input.String()), io.Discard))
+	require.Equal(t, expected, kept)
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unix_grep_lite/internal/domain"
+)
+
+// FileTypes реестр типов файлов: имя типа и glob-шаблоны имён его файлов (флаги -t, -T и --type-add)
+type FileTypes map[string][]string
+
+// defaultFileTypes встроенные типы файлов
+var defaultFileTypes = FileTypes{
+	"c":        {"*.c", "*.h"},
+	"cpp":      {"*.cpp", "*.cc", "*.cxx", "*.hpp", "*.hh", "*.hxx"},
+	"css":      {"*.css"},
+	"go":       {"*.go"},
+	"html":     {"*.html", "*.htm"},
+	"java":     {"*.java"},
+	"js":       {"*.js", "*.mjs", "*.cjs", "*.jsx"},
+	"json":     {"*.json"},
+	"make":     {"Makefile", "makefile", "GNUmakefile", "*.mk"},
+	"markdown": {"*.md", "*.markdown"},
+	"md":       {"*.md", "*.markdown"},
+	"py":       {"*.py", "*.pyi"},
+	"rust":     {"*.rs"},
+	"sh":       {"*.sh", "*.bash", "*.zsh"},
+	"sql":      {"*.sql"},
+	"ts":       {"*.ts", "*.tsx"},
+	"txt":      {"*.txt"},
+	"xml":      {"*.xml"},
+	"yaml":     {"*.yaml", "*.yml"},
+}
+
+// DefaultFileTypes возвращает копию реестра встроенных типов, которую можно расширять
+func DefaultFileTypes() FileTypes {
+	types := make(FileTypes, len(defaultFileTypes))
+	for name, globs := range defaultFileTypes {
+		types[name] = slices.Clone(globs)
+	}
+	return types
+}
+
+// Add добавляет шаблоны к типу по определению вида "name:glob[,glob...]" (флаг --type-add)
+func (t FileTypes) Add(def string) error {
+	name, globs, ok := strings.Cut(def, ":")
+	if !ok || name == "" || globs == "" {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidTypeDef, def)
+	}
+	for _, glob := range strings.Split(globs, ",") {
+		if glob != "" && !slices.Contains(t[name], glob) {
+			t[name] = append(t[name], glob)
+		}
+	}
+	return nil
+}
+
+// Globs возвращает шаблоны всех перечисленных типов
+func (t FileTypes) Globs(names []string) ([]string, error) {
+	var globs []string
+	for _, name := range names {
+		typeGlobs, ok := t[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", domain.ErrUnknownType, name)
+		}
+		globs = append(globs, typeGlobs...)
+	}
+	return globs, nil
+}
+
+// WriteList выводит типы в порядке имён в виде "name: glob, glob" (флаг --type-list)
+func (t FileTypes) WriteList(w io.Writer) error {
+	var sb strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(t)) {
+		sb.WriteString(name + ": " + strings.Join(t[name], ", ") + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
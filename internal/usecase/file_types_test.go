@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTypesAdd(t *testing.T) {
+	tests := []struct {
+		name        string
+		def         string
+		typeName    string
+		expected    []string
+		expectedErr error
+	}{
+		{name: "new type", def: "proto:*.proto", typeName: "proto", expected: []string{"*.proto"}},
+		{name: "several globs", def: "web:*.html,*.css", typeName: "web", expected: []string{"*.html", "*.css"}},
+		{name: "extend builtin", def: "go:go.mod", typeName: "go", expected: []string{"*.go", "go.mod"}},
+		{name: "duplicate glob", def: "go:*.go", typeName: "go", expected: []string{"*.go"}},
+		{name: "missing globs", def: "proto:", expectedErr: domain.ErrInvalidTypeDef},
+		{name: "missing colon", def: "proto", expectedErr: domain.ErrInvalidTypeDef},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			types := DefaultFileTypes()
+			err := types.Add(tt.def)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, types[tt.typeName])
+		})
+	}
+}
+
+func TestFileTypesAddDoesNotChangeDefaults(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, DefaultFileTypes().Add("go:go.mod"))
+	require.Equal(t, []string{"*.go"}, DefaultFileTypes()["go"])
+}
+
+func TestFileTypesGlobs(t *testing.T) {
+	t.Parallel()
+
+	types := DefaultFileTypes()
+	globs, err := types.Globs([]string{"go", "md"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"*.go", "*.md", "*.markdown"}, globs)
+
+	_, err = types.Globs([]string{"nope"})
+	require.ErrorIs(t, err, domain.ErrUnknownType)
+}
+
+func TestFileTypesWriteList(t *testing.T) {
+	t.Parallel()
+
+	types := FileTypes{"md": {"*.md"}, "go": {"*.go", "go.mod"}}
+	var sb strings.Builder
+	require.NoError(t, types.WriteList(&sb))
+	require.Equal(t, "go: *.go, go.mod\nmd: *.md\n", sb.String())
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"io"
+)
+
+// hasSelectedLine проверяет, есть ли в r хотя бы одна выбранная строка (флаги -l и -L).
+// Чтение прекращается на первой такой строке, а при -m 0 вход не читается.
+func (m *Matcher) hasSelectedLine(r io.Reader) (bool, error) {
+	if m.maxCount() == 0 {
+		return false, nil
+	}
+	sc := m.scanLines(r)
+	for m.scanSelected(sc, 0) {
+		if m.lineIsSelected(sc.Line()) {
+			return true, nil
+		}
+	}
+	return false, sc.Err()
+}
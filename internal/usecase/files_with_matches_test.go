@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesWithMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		pattern  string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "files with matches - found",
+			input:    "hello\ntest\nworld",
+			pattern:  "test",
+			opts:     domain.GrepOptions{FilesWithMatches: true},
+			expected: "a.txt\n",
+		},
+		{
+			name:     "files with matches - not found",
+			input:    "hello\nworld",
+			pattern:  "test",
+			opts:     domain.GrepOptions{FilesWithMatches: true},
+			expected: "",
+		},
+		{
+			name:     "files without match - found",
+			input:    "hello\ntest\nworld",
+			pattern:  "test",
+			opts:     domain.GrepOptions{FilesWithoutMatch: true},
+			expected: "",
+		},
+		{
+			name:     "files without match - not found",
+			input:    "hello\nworld",
+			pattern:  "test",
+			opts:     domain.GrepOptions{FilesWithoutMatch: true},
+			expected: "a.txt\n",
+		},
+		{
+			name:     "files with matches - invert",
+			input:    "test\ntest",
+			pattern:  "test",
+			opts:     domain.GrepOptions{FilesWithMatches: true, InvertMatch: true},
+			expected: "",
+		},
+		{
+			name:     "files with matches overrides count and line numbers",
+			input:    "test\ntest",
+			pattern:  "test",
+			opts:     domain.GrepOptions{FilesWithMatches: true, Count: true, LineNumber: true},
+			expected: "a.txt\n",
+		},
+		{
+			name:     "files without match - empty input",
+			input:    "",
+			pattern:  "test",
+			opts:     domain.GrepOptions{FilesWithoutMatch: true},
+			expected: "a.txt\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			require.NoError(t, matcher.SearchFile("a.txt", strings.NewReader(tt.input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestFilesWithMatchesStopsReading(t *testing.T) {
+	t.Parallel()
+
+	// Ошибка чтения после совпадения означает, что поиск дочитал вход до конца
+	readErr := errors.New("read past first match")
+	newInput := func() io.Reader {
+		return io.MultiReader(strings.NewReader("hello\ntest\n"), iotest.ErrReader(readErr))
+	}
+
+	matcher, err := NewMatcher("test", domain.GrepOptions{FilesWithMatches: true})
+	require.NoError(t, err, "Failed to create matcher")
+
+	var out strings.Builder
+	require.NoError(t, matcher.SearchFile("a.txt", newInput(), &out))
+	require.Equal(t, "a.txt\n", out.String())
+
+	matcher, err = NewMatcher("test", domain.GrepOptions{FilesWithoutMatch: true})
+	require.NoError(t, err, "Failed to create matcher")
+
+	out.Reset()
+	require.NoError(t, matcher.SearchFile("a.txt", newInput(), &out))
+	require.Empty(t, out.String())
+
+	// Без -l вход читается полностью
+	matcher, err = NewMatcher("test", domain.GrepOptions{Count: true})
+	require.NoError(t, err, "Failed to create matcher")
+	require.ErrorIs(t, matcher.SearchFile("a.txt", newInput(), io.Discard), readErr)
+}
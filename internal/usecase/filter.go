@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlobSuffix проверяет, совпадает ли glob с суффиксом имени name, как в GNU grep:
+// суффиксом считается всё имя или его часть, начинающаяся сразу после "/".
+// Некорректный glob не совпадает ни с одним именем.
+func matchGlobSuffix(glob, name string) bool {
+	for {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+		i := strings.IndexByte(name, '/')
+		if i < 0 {
+			return false
+		}
+		name = name[i+1:]
+	}
+}
+
+// matchAnyGlob проверяет, совпадает ли с именем name хотя бы один из globs
+func matchAnyGlob(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matchGlobSuffix(glob, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileFiltered проверяет, исключён ли файл фильтрами --include, --exclude, -t и -T
+func (w *walker) fileFiltered(name string) bool {
+	opts := w.opts
+	if len(opts.Include) > 0 && !matchAnyGlob(opts.Include, name) {
+		return true
+	}
+	if len(opts.TypeGlobs) > 0 && !matchAnyGlob(opts.TypeGlobs, name) {
+		return true
+	}
+	return matchAnyGlob(opts.Exclude, name) || matchAnyGlob(opts.TypeNotGlobs, name)
+}
+
+// dirFiltered проверяет, исключён ли вложенный каталог фильтром --exclude-dir
+func (w *walker) dirFiltered(name string) bool {
+	return matchAnyGlob(w.opts.ExcludeDir, name)
+}
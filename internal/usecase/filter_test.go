@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlobSuffix(t *testing.T) {
+	tests := []struct {
+		name     string
+		glob     string
+		path     string
+		expected bool
+	}{
+		{name: "base name", glob: "*.go", path: "src/pkg/main.go", expected: true},
+		{name: "whole name", glob: "main.go", path: "main.go", expected: true},
+		{name: "suffix with directory", glob: "pkg/*.go", path: "/src/pkg/main.go", expected: true},
+		{name: "no partial component", glob: "ain.go", path: "src/main.go", expected: false},
+		{name: "no match", glob: "*.md", path: "src/main.go", expected: false},
+		{name: "invalid glob", glob: "[", path: "[", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, matchGlobSuffix(tt.glob, tt.path))
+		})
+	}
+}
+
+func TestWalkFilesFilters(t *testing.T) {
+	root := makeTree(t)
+
+	tests := []struct {
+		name     string
+		operands []string
+		opts     domain.GrepOptions
+		expected []string
+	}{
+		{
+			name:     "include",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, Include: []string{"x", "top"}},
+			expected: []string{root + "/a/x", root + "/top"},
+		},
+		{
+			name:     "exclude",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, Exclude: []string{"y"}},
+			expected: []string{root + "/a/x", root + "/top"},
+		},
+		{
+			name:     "exclude file operand",
+			operands: []string{root + "/top", root + "/a/x"},
+			opts:     domain.GrepOptions{Exclude: []string{"top"}},
+			expected: []string{root + "/a/x"},
+		},
+		{
+			name:     "exclude dir",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, ExcludeDir: []string{"b"}},
+			expected: []string{root + "/a/x", root + "/top"},
+		},
+		{
+			name:     "exclude dir keeps operand",
+			operands: []string{root + "/a"},
+			opts:     domain.GrepOptions{Recursive: true, ExcludeDir: []string{"a"}},
+			expected: []string{root + "/a/b/y", root + "/a/x"},
+		},
+		{
+			name:     "type globs",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, TypeGlobs: []string{"y"}},
+			expected: []string{root + "/a/b/y"},
+		},
+		{
+			name:     "type not globs",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, TypeNotGlobs: []string{"y", "x"}},
+			expected: []string{root + "/top"},
+		},
+		{
+			name:     "stdin is not filtered",
+			operands: []string{"-"},
+			opts:     domain.GrepOptions{Include: []string{"*.go"}},
+			expected: []string{"-"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, errs := collectWalk(tt.operands, tt.opts)
+			require.Empty(t, errs)
+			require.Equal(t, tt.expected, files)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"slices"
+	"unix_grep_lite/internal/domain"
+)
+
+// fixedEngine движок фиксированных строк (флаг -F): каждый паттерн ищется отдельно
+type fixedEngine struct {
+	patterns   []string // при -i приведены функцией foldCase
+	ignoreCase bool
+	word       bool // -w
+	line       bool // -x
+}
+
+func newFixedEngine(patterns []string, opts domain.GrepOptions) (MatchEngine, error) {
+	e := &fixedEngine{
+		patterns:   make([]string, 0, len(patterns)),
+		ignoreCase: opts.IgnoreCase,
+		word:       opts.WordRegexp,
+		line:       opts.LineRegexp,
+	}
+	for _, pattern := range patterns {
+		if opts.IgnoreCase {
+			pattern, _ = foldCase(pattern, false)
+		}
+		e.patterns = append(e.patterns, pattern)
+	}
+	return e, nil
+}
+
+func (e *fixedEngine) Match(line string) bool {
+	if e.ignoreCase {
+		line, _ = foldCase(line, false)
+	}
+	for _, pattern := range e.patterns {
+		if e.index(line, pattern, 0) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *fixedEngine) Find(line string) []int {
+	var offs []int
+	if e.ignoreCase {
+		line, offs = foldCase(line, true)
+	}
+	start, end := e.leftmostLongest(line, 0)
+	if start < 0 {
+		return nil
+	}
+	return unfoldLocs([][]int{{start, end}}, offs)[0]
+}
+
+func (e *fixedEngine) FindAll(line string) [][]int {
+	var offs []int
+	if e.ignoreCase {
+		line, offs = foldCase(line, true)
+	}
+	var matches [][]int
+	for pos := 0; pos < len(line); {
+		start, end := e.leftmostLongest(line, pos)
+		if start < 0 {
+			break
+		}
+		matches = append(matches, []int{start, end})
+		pos = end
+	}
+	return unfoldLocs(matches, offs)
+}
+
+// leftmostLongest возвращает самое левое, а из них самое длинное непустое вхождение
+// паттернов с позиции pos или -1, -1
+func (e *fixedEngine) leftmostLongest(line string, pos int) (start, end int) {
+	start, end = -1, -1
+	for _, pattern := range e.patterns {
+		if pattern == "" {
+			continue
+		}
+		i := e.index(line, pattern, pos)
+		if i < 0 {
+			continue
+		}
+		if start < 0 || i < start || (i == start && i+len(pattern) > end) {
+			start, end = i, i+len(pattern)
+		}
+	}
+	return start, end
+}
+
+func (e *fixedEngine) LiteralPrefixes() []string {
+	// При -i совпадение может отличаться от паттерна регистром, пустой паттерн совпадает везде
+	if e.ignoreCase || slices.Contains(e.patterns, "") {
+		return nil
+	}
+	return e.patterns
+}
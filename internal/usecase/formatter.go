@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"io"
+	"unix_grep_lite/internal/domain"
+)
+
+// Formatter выводит результаты поиска по одному входу. Match вызывается для строк
+// в порядке входа, End - после всех результатов успешно прочитанного входа,
+// Flush - в конце обработки входа, в том числе после ошибки.
+type Formatter interface {
+	Match(match domain.Match) error // выбранная или контекстная строка
+	Count(count domain.Count) error // результат подсчёта (флаг -c)
+	FileName() error                // имя входа (флаги -l и -L)
+	BinaryMatches() error           // совпадение в бинарном входе
+	End() error
+	Flush() error
+}
+
+// NewFormatter создаёт форматировщик вывода, выбранный параметрами opts:
+// JSON Lines при --json, иначе текстовый вывод в формате GNU grep.
+// name - имя входа в выводе.
+func NewFormatter(w io.Writer, name string, opts domain.GrepOptions) Formatter {
+	if opts.JSON {
+		return newJSONPrinter(w, name)
+	}
+	return newPrinter(w, name, opts)
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFormatter записывает вызовы форматировщика
+type recordingFormatter struct {
+	calls []string
+}
+
+func (f *recordingFormatter) Match(match domain.Match) error {
+	kind := "match"
+	if match.Kind == domain.KindContext {
+		kind = "context"
+	}
+	f.calls = append(f.calls, kind+" "+match.Text)
+	return nil
+}
+
+func (f *recordingFormatter) Count(count domain.Count) error {
+	f.calls = append(f.calls, "count "+strconv.Itoa(count.Lines))
+	return nil
+}
+
+func (f *recordingFormatter) FileName() error {
+	f.calls = append(f.calls, "filename")
+	return nil
+}
+
+func (f *recordingFormatter) BinaryMatches() error {
+	f.calls = append(f.calls, "binary")
+	return nil
+}
+
+func (f *recordingFormatter) End() error {
+	f.calls = append(f.calls, "end")
+	return nil
+}
+
+func (f *recordingFormatter) Flush() error {
+	f.calls = append(f.calls, "flush")
+	return nil
+}
+
+func TestSearchFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     domain.GrepOptions
+		expected []string
+	}{
+		{
+			name:     "matches",
+			input:    "foo\nbar\nfoo",
+			opts:     domain.GrepOptions{},
+			expected: []string{"match foo", "match foo", "end", "flush"},
+		},
+		{
+			name:     "context",
+			input:    "a\nfoo\nb",
+			opts:     domain.GrepOptions{AfterContext: true, NumAfter: 1},
+			expected: []string{"match foo", "context b", "end", "flush"},
+		},
+		{
+			name:     "count",
+			input:    "foo\nfoo",
+			opts:     domain.GrepOptions{Count: true},
+			expected: []string{"count 2", "end", "flush"},
+		},
+		{
+			name:     "files with matches",
+			input:    "foo",
+			opts:     domain.GrepOptions{FilesWithMatches: true},
+			expected: []string{"filename", "end", "flush"},
+		},
+		{
+			name:     "binary",
+			input:    "foo\x00",
+			opts:     domain.GrepOptions{},
+			expected: []string{"binary", "end", "flush"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcher("foo", tt.opts)
+			require.NoError(t, err)
+
+			f := &recordingFormatter{}
+			require.NoError(t, m.SearchFormat("a.txt", strings.NewReader(tt.input), f))
+			require.Equal(t, tt.expected, f.calls)
+		})
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	t.Parallel()
+
+	require.IsType(t, &printer{}, NewFormatter(io.Discard, "a.txt", domain.GrepOptions{}))
+	require.IsType(t, &jsonPrinter{}, NewFormatter(io.Discard, "a.txt", domain.GrepOptions{JSON: true}))
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Файлы с правилами исключения, в порядке возрастания приоритета внутри каталога.
+// .git/info/exclude читается только в корне репозитория (каталоге, содержащем .git).
+var ignoreFiles = []string{".gitignore", ".ignore"}
+
+const (
+	gitDir         = ".git"
+	gitExcludeFile = gitDir + "/info/exclude"
+)
+
+// ignoreRule правило исключения в синтаксисе gitignore
+type ignoreRule struct {
+	re      *regexp.Regexp // путь относительно каталога файла правил
+	negate  bool           // "!pattern": возврат ранее исключённого пути
+	dirOnly bool           // "pattern/": правило только для каталогов
+}
+
+// ignoreRules правила одного каталога; при нескольких совпадениях действует последнее
+type ignoreRules []ignoreRule
+
+// match проверяет путь rel относительно каталога правил. ok сообщает, совпало ли
+// хотя бы одно правило; ignored - исключён ли путь последним совпавшим правилом.
+func (rules ignoreRules) match(rel string, isDir bool) (ignored, ok bool) {
+	for i := len(rules) - 1; i >= 0; i-- {
+		rule := rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			return !rule.negate, true
+		}
+	}
+	return false, false
+}
+
+// readIgnoreDir читает правила исключения каталога dir.
+// Отсутствующие и нечитаемые файлы правил пропускаются.
+func readIgnoreDir(dir string) ignoreRules {
+	var rules ignoreRules
+	names := ignoreFiles
+	if isRepoRoot(dir) {
+		names = append([]string{gitExcludeFile}, names...)
+	}
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, parseIgnore(f)...)
+		_ = f.Close()
+	}
+	return rules
+}
+
+// isRepoRoot проверяет, является ли dir корнем git-репозитория
+func isRepoRoot(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, gitDir))
+	return err == nil
+}
+
+// parseIgnore разбирает файл правил в синтаксисе gitignore. Некорректные шаблоны пропускаются.
+func parseIgnore(r io.Reader) ignoreRules {
+	var rules ignoreRules
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if rule, ok := parseIgnoreLine(sc.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine разбирает одну строку файла правил
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimIgnoreSpaces(line)
+	if line == "" || line[0] == '#' {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	switch {
+	case line[0] == '!':
+		rule.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// Шаблон со слешем в начале или середине привязан к каталогу файла правил,
+	// без слеша - совпадает с именем на любой глубине
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored && !strings.HasPrefix(expr, "(?:.*/)?") {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// trimIgnoreSpaces удаляет завершающие пробелы, кроме экранированных обратным слешем
+func trimIgnoreSpaces(line string) string {
+	end := len(line)
+	for end > 0 && line[end-1] == ' ' {
+		if end > 1 && line[end-2] == '\\' {
+			break
+		}
+		end--
+	}
+	return line[:end]
+}
+
+// globToRegexp преобразует glob-шаблон gitignore в регулярное выражение:
+// "*" и "?" не совпадают с "/", "**" в виде "**/", "/**/" и "/**" охватывает любое число каталогов
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "**" && i > 0 && glob[i-1] == '/':
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			class, n := globClass(glob[i:])
+			if n == 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			sb.WriteString(class)
+			i += n - 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return sb.String()
+}
+
+// globClass преобразует класс символов "[...]" в начале s в регулярное выражение
+// и возвращает число поглощённых байтов или 0, если класс не закрыт
+func globClass(s string) (string, int) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	i := 1
+	if i < len(s) && (s[i] == '!' || s[i] == '^') {
+		sb.WriteString("^/")
+		i++
+	}
+	// "]" сразу после открывающей скобки входит в класс
+	if i < len(s) && s[i] == ']' {
+		sb.WriteString(`\]`)
+		i++
+	}
+	for ; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == ']':
+			sb.WriteString("]")
+			return sb.String(), i + 1
+		case c == '\\' && i+1 < len(s):
+			i++
+			sb.WriteString(regexp.QuoteMeta(s[i : i+1]))
+		case c == '[' || c == '^':
+			sb.WriteString(`\` + string(c))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0
+}
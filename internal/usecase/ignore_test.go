@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreRulesMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{name: "name at any depth", rules: "*.log", path: "a/b/app.log", expected: true},
+		{name: "star does not cross slash", rules: "a/*.log", path: "a/b/app.log", expected: false},
+		{name: "anchored with leading slash", rules: "/build", path: "build", isDir: true, expected: true},
+		{name: "anchored does not match nested", rules: "/build", path: "src/build", isDir: true, expected: false},
+		{name: "middle slash anchors", rules: "doc/api", path: "x/doc/api", expected: false},
+		{name: "directory only matches directory", rules: "vendor/", path: "vendor", isDir: true, expected: true},
+		{name: "directory only skips file", rules: "vendor/", path: "vendor", isDir: false, expected: false},
+		{name: "negation re-includes", rules: "*.log\n!keep.log", path: "keep.log", expected: false},
+		{name: "last rule wins", rules: "!keep.log\n*.log", path: "keep.log", expected: true},
+		{name: "leading double star", rules: "**/cache", path: "a/b/cache", isDir: true, expected: true},
+		{name: "trailing double star", rules: "out/**", path: "out/a/b.txt", expected: true},
+		{name: "trailing double star excludes contents only", rules: "out/**", path: "out", isDir: true, expected: false},
+		{name: "middle double star", rules: "a/**/z", path: "a/b/c/z", expected: true},
+		{name: "middle double star matches zero dirs", rules: "a/**/z", path: "a/z", expected: true},
+		{name: "question mark", rules: "?.txt", path: "a.txt", expected: true},
+		{name: "character class", rules: "[abc].txt", path: "b.txt", expected: true},
+		{name: "negated character class", rules: "[!abc].txt", path: "b.txt", expected: false},
+		{name: "comment", rules: "# *.txt", path: "a.txt", expected: false},
+		{name: "escaped hash", rules: `\#notes`, path: "#notes", expected: true},
+		{name: "escaped exclamation", rules: `\!bang`, path: "!bang", expected: true},
+		{name: "trailing spaces trimmed", rules: "a.txt  ", path: "a.txt", expected: true},
+		{name: "escaped trailing space kept", rules: `a\ `, path: "a ", expected: true},
+		{name: "regexp metacharacters are literal", rules: "a+b.txt", path: "aab.txt", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ignored, _ := parseIgnore(strings.NewReader(tt.rules)).match(tt.path, tt.isDir)
+			require.Equal(t, tt.expected, ignored)
+		})
+	}
+}
+
+// makeIgnoreTree создаёт репозиторий с файлами правил исключения
+func makeIgnoreTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":        "*.log\n/build/\nvendor/\n!keep.log\n/src/only.txt\n",
+		".ignore":           "secret.txt\n",
+		".git/info/exclude": "local.txt\n",
+		".hidden":           "foo\n",
+		"a.txt":             "foo\n",
+		"app.log":           "foo\n",
+		"keep.log":          "foo\n",
+		"local.txt":         "foo\n",
+		"secret.txt":        "foo\n",
+		"build/out.txt":     "foo\n",
+		"src/build/x.txt":   "foo\n",
+		"src/vendor/v.txt":  "foo\n",
+		"src/.gitignore":    "!*.log\ngen.txt\n",
+		"src/app.log":       "foo\n",
+		"src/gen.txt":       "foo\n",
+		"src/local.txt":     "foo\n",
+		"src/only.txt":      "foo\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return root
+}
+
+func TestWalkFilesIgnore(t *testing.T) {
+	root := makeIgnoreTree(t)
+
+	tests := []struct {
+		name     string
+		operands []string
+		opts     domain.GrepOptions
+		expected []string
+	}{
+		{
+			name:     "ignore files and hidden entries",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true},
+			expected: []string{root + "/a.txt", root + "/keep.log", root + "/src/app.log", root + "/src/build/x.txt"},
+		},
+		{
+			name:     "hidden",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, Hidden: true},
+			expected: []string{
+				root + "/.gitignore", root + "/.hidden", root + "/.ignore",
+				root + "/a.txt", root + "/keep.log", root + "/src/.gitignore", root + "/src/app.log", root + "/src/build/x.txt",
+			},
+		},
+		{
+			name:     "hidden without ignore",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, Hidden: true, NoIgnore: true},
+			expected: []string{
+				root + "/.git/info/exclude", root + "/.gitignore", root + "/.hidden", root + "/.ignore",
+				root + "/a.txt", root + "/app.log", root + "/build/out.txt", root + "/keep.log", root + "/local.txt",
+				root + "/secret.txt", root + "/src/.gitignore", root + "/src/app.log", root + "/src/build/x.txt",
+				root + "/src/gen.txt", root + "/src/local.txt", root + "/src/only.txt", root + "/src/vendor/v.txt",
+			},
+		},
+		{
+			name:     "no ignore",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, NoIgnore: true},
+			expected: []string{
+				root + "/a.txt", root + "/app.log", root + "/build/out.txt", root + "/keep.log", root + "/local.txt",
+				root + "/secret.txt", root + "/src/app.log", root + "/src/build/x.txt", root + "/src/gen.txt",
+				root + "/src/local.txt", root + "/src/only.txt", root + "/src/vendor/v.txt",
+			},
+		},
+		{
+			name:     "operand below repository root",
+			operands: []string{root + "/src"},
+			opts:     domain.GrepOptions{Recursive: true},
+			expected: []string{root + "/src/app.log", root + "/src/build/x.txt"},
+		},
+		{
+			name:     "operand below repository root without ignore",
+			operands: []string{root + "/src/"},
+			opts:     domain.GrepOptions{Recursive: true, NoIgnore: true},
+			expected: []string{
+				root + "/src/app.log", root + "/src/build/x.txt", root + "/src/gen.txt",
+				root + "/src/local.txt", root + "/src/only.txt", root + "/src/vendor/v.txt",
+			},
+		},
+		{
+			name:     "operands are not filtered",
+			operands: []string{root + "/app.log", root + "/.hidden"},
+			opts:     domain.GrepOptions{Recursive: true},
+			expected: []string{root + "/app.log", root + "/.hidden"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, errs := collectWalk(tt.operands, tt.opts)
+			require.Empty(t, errs)
+			require.Equal(t, tt.expected, files)
+		})
+	}
+}
+
+func TestWalkFilesIgnoreFromSubdir(t *testing.T) {
+	root := makeIgnoreTree(t)
+	t.Chdir(filepath.Join(root, "src", "build"))
+	// Файлы, исключённые правилами корня репозитория (vendor/, local.txt из .git/info/exclude)
+	// и каталога src (gen.txt); app.log возвращён отрицанием в src/.gitignore
+	for _, name := range []string{"app.log", "gen.txt", "local.txt", "vendor/v.txt"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		require.NoError(t, os.WriteFile(name, []byte("foo\n"), 0o644))
+	}
+
+	// Правила каталогов над операндом действуют и при поиске из вложенного каталога
+	files, errs := collectWalk(nil, domain.GrepOptions{Recursive: true})
+	require.Empty(t, errs)
+	require.Equal(t, []string{"app.log", "x.txt"}, files)
+
+	t.Chdir(root)
+	files, errs = collectWalk([]string{"src/build"}, domain.GrepOptions{Recursive: true})
+	require.Empty(t, errs)
+	require.Equal(t, []string{"src/build/app.log", "src/build/x.txt"}, files)
+}
+
+func TestWalkFilesIgnoreOutsideRepository(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		".gitignore":    "*.log\n",
+		"sub/.ignore":   "b.txt\n",
+		"sub/app.log":   "foo\n",
+		"sub/a.txt":     "foo\n",
+		"sub/b.txt":     "foo\n",
+		"sub/c/app.log": "foo\n",
+	} {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	// Вне репозитория действуют правила обходимых каталогов, но не каталогов над операндом
+	files, errs := collectWalk([]string{root + "/sub"}, domain.GrepOptions{Recursive: true})
+	require.Empty(t, errs)
+	require.Equal(t, []string{root + "/sub/a.txt", root + "/sub/app.log", root + "/sub/c/app.log"}, files)
+
+	files, errs = collectWalk([]string{root}, domain.GrepOptions{Recursive: true})
+	require.Empty(t, errs)
+	require.Equal(t, []string{root + "/sub/a.txt"}, files)
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+	"unix_grep_lite/internal/domain"
+)
+
+// События вывода --json (JSON Lines, один объект на строку). Схема стабильна:
+// поля могут добавляться, но существующие не удаляются и не меняют смысл.
+//
+//	{"type":"begin","data":{"path":{"text":"a.txt"}}}
+//	{"type":"match","data":{"path":{"text":"a.txt"},"lines":{"text":"foo bar"},"line_number":2,"absolute_offset":4,"submatches":[{"match":{"text":"bar"},"start":4,"end":7}]}}
+//	{"type":"context","data":{"path":{"text":"a.txt"},"lines":{"text":"baz"},"line_number":3,"absolute_offset":12,"submatches":[]}}
+//	{"type":"end","data":{"path":{"text":"a.txt"},"stats":{"matched_lines":1,"matches":1}}}
+//
+// begin и end выводятся только для входов, в которых есть выбранные строки.
+// Текст строк не содержит перевода строки. Текст, не являющийся корректным UTF-8,
+// передаётся в поле "bytes" в base64 вместо поля "text".
+const (
+	jsonTypeBegin   = "begin"
+	jsonTypeMatch   = "match"
+	jsonTypeContext = "context"
+	jsonTypeEnd     = "end"
+)
+
+// jsonEvent событие вывода --json
+type jsonEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// jsonText строка UTF-8 или base64 произвольных байтов
+type jsonText struct {
+	Text  *string `json:"text,omitempty"`
+	Bytes *string `json:"bytes,omitempty"`
+}
+
+func newJSONText(s string) *jsonText {
+	if utf8.ValidString(s) {
+		return &jsonText{Text: &s}
+	}
+	b := base64.StdEncoding.EncodeToString([]byte(s))
+	return &jsonText{Bytes: &b}
+}
+
+type jsonBegin struct {
+	Path *jsonText `json:"path,omitempty"`
+}
+
+type jsonLine struct {
+	Path           *jsonText      `json:"path,omitempty"`
+	Lines          *jsonText      `json:"lines"`
+	LineNumber     int            `json:"line_number"`
+	AbsoluteOffset int64          `json:"absolute_offset"`
+	Submatches     []jsonSubmatch `json:"submatches"`
+}
+
+type jsonSubmatch struct {
+	Match *jsonText `json:"match"`
+	Start int       `json:"start"` // смещение в байтах от начала строки
+	End   int       `json:"end"`
+}
+
+type jsonEnd struct {
+	Path  *jsonText `json:"path,omitempty"`
+	Stats jsonStats `json:"stats"`
+}
+
+type jsonStats struct {
+	MatchedLines int `json:"matched_lines"`
+	Matches      int `json:"matches"`
+}
+
+// jsonPrinter форматирует результаты поиска по одному входу в события JSON Lines (флаг --json)
+type jsonPrinter struct {
+	w     *bufio.Writer
+	name  string    // путь входа в событиях
+	buf   []byte    // переиспользуемый буфер для сборки события
+	begun bool      // выведено ли событие begin
+	stats jsonStats // статистика для события end
+}
+
+func newJSONPrinter(w io.Writer, name string) *jsonPrinter {
+	return &jsonPrinter{w: bufio.NewWriter(w), name: name}
+}
+
+// path возвращает путь входа для событий или nil, если имя не задано
+func (p *jsonPrinter) path() *jsonText {
+	if p.name == "" {
+		return nil
+	}
+	return newJSONText(p.name)
+}
+
+// Match выводит событие match или context, предваряя первое событие входа событием begin
+func (p *jsonPrinter) Match(match domain.Match) error {
+	if !p.begun {
+		p.begun = true
+		if err := p.encode(jsonTypeBegin, jsonBegin{Path: p.path()}); err != nil {
+			return err
+		}
+	}
+
+	submatches := make([]jsonSubmatch, 0, len(match.Submatches))
+	for _, s := range match.Submatches {
+		submatches = append(submatches, jsonSubmatch{
+			Match: newJSONText(match.Text[s.Start:s.End]),
+			Start: s.Start,
+			End:   s.End,
+		})
+	}
+
+	eventType := jsonTypeContext
+	if match.Kind == domain.KindMatch {
+		eventType = jsonTypeMatch
+		p.stats.MatchedLines++
+		p.stats.Matches += len(submatches)
+	}
+	return p.encode(eventType, jsonLine{
+		Path:           p.path(),
+		Lines:          newJSONText(match.Text),
+		LineNumber:     match.LineNumber,
+		AbsoluteOffset: match.Offset,
+		Submatches:     submatches,
+	})
+}
+
+// Count не выводит ничего: --json несовместим с -c (см. jsonConflicts)
+func (p *jsonPrinter) Count(domain.Count) error {
+	return nil
+}
+
+// FileName не выводит ничего: --json несовместим с -l и -L (см. jsonConflicts)
+func (p *jsonPrinter) FileName() error {
+	return nil
+}
+
+// BinaryMatches не выводит ничего: строки бинарных входов выводятся событиями как есть
+func (p *jsonPrinter) BinaryMatches() error {
+	return nil
+}
+
+// End выводит событие end со статистикой, если для входа было событие begin
+func (p *jsonPrinter) End() error {
+	if !p.begun {
+		return nil
+	}
+	return p.encode(jsonTypeEnd, jsonEnd{Path: p.path(), Stats: p.stats})
+}
+
+// encode выводит событие отдельной строкой JSON
+func (p *jsonPrinter) encode(eventType string, data any) error {
+	b, err := json.Marshal(jsonEvent{Type: eventType, Data: data})
+	if err != nil {
+		return err
+	}
+	p.buf = append(p.buf[:0], b...)
+	p.buf = append(p.buf, '\n')
+	if _, err := p.w.Write(p.buf); err != nil {
+		return &domain.WriteError{Err: err}
+	}
+	return nil
+}
+
+// Flush сбрасывает буферизованный вывод
+func (p *jsonPrinter) Flush() error {
+	if err := p.w.Flush(); err != nil {
+		return &domain.WriteError{Err: err}
+	}
+	return nil
+}
+
+// jsonConflicts проверяет, что --json не сочетается с режимами, не выводящими строки
+func jsonConflicts(opts domain.GrepOptions) bool {
+	return opts.JSON && (opts.Count || opts.FilesWithMatches || opts.FilesWithoutMatch)
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		pattern  string
+		opts     domain.GrepOptions
+		expected []string
+	}{
+		{
+			name:    "match events with stats",
+			input:   "hello\nfoo bar foo\nbar",
+			pattern: "foo",
+			opts:    domain.GrepOptions{JSON: true},
+			expected: []string{
+				`{"type":"begin","data":{"path":{"text":"a.txt"}}}`,
+				`{"type":"match","data":{"path":{"text":"a.txt"},"lines":{"text":"foo bar foo"},"line_number":2,"absolute_offset":6,"submatches":[{"match":{"text":"foo"},"start":0,"end":3},{"match":{"text":"foo"},"start":8,"end":11}]}}`,
+				`{"type":"end","data":{"path":{"text":"a.txt"},"stats":{"matched_lines":1,"matches":2}}}`,
+			},
+		},
+		{
+			name:    "context events without separators",
+			input:   "a\nfoo\nb\nc\nd\nfoo",
+			pattern: "foo",
+			opts:    domain.GrepOptions{JSON: true, AfterContext: true, NumAfter: 1},
+			expected: []string{
+				`{"type":"begin","data":{"path":{"text":"a.txt"}}}`,
+				`{"type":"match","data":{"path":{"text":"a.txt"},"lines":{"text":"foo"},"line_number":2,"absolute_offset":2,"submatches":[{"match":{"text":"foo"},"start":0,"end":3}]}}`,
+				`{"type":"context","data":{"path":{"text":"a.txt"},"lines":{"text":"b"},"line_number":3,"absolute_offset":6,"submatches":[]}}`,
+				`{"type":"match","data":{"path":{"text":"a.txt"},"lines":{"text":"foo"},"line_number":6,"absolute_offset":12,"submatches":[{"match":{"text":"foo"},"start":0,"end":3}]}}`,
+				`{"type":"end","data":{"path":{"text":"a.txt"},"stats":{"matched_lines":2,"matches":2}}}`,
+			},
+		},
+		{
+			name:    "inverted match has no submatches",
+			input:   "foo\nbar",
+			pattern: "foo",
+			opts:    domain.GrepOptions{JSON: true, InvertMatch: true},
+			expected: []string{
+				`{"type":"begin","data":{"path":{"text":"a.txt"}}}`,
+				`{"type":"match","data":{"path":{"text":"a.txt"},"lines":{"text":"bar"},"line_number":2,"absolute_offset":4,"submatches":[]}}`,
+				`{"type":"end","data":{"path":{"text":"a.txt"},"stats":{"matched_lines":1,"matches":0}}}`,
+			},
+		},
+		{
+			name:    "invalid UTF-8 as base64 bytes",
+			input:   "x\xffy",
+			pattern: "x",
+			opts:    domain.GrepOptions{JSON: true},
+			expected: []string{
+				`{"type":"begin","data":{"path":{"text":"a.txt"}}}`,
+				`{"type":"match","data":{"path":{"text":"a.txt"},"lines":{"bytes":"eP95"},"line_number":1,"absolute_offset":0,"submatches":[{"match":{"text":"x"},"start":0,"end":1}]}}`,
+				`{"type":"end","data":{"path":{"text":"a.txt"},"stats":{"matched_lines":1,"matches":1}}}`,
+			},
+		},
+		{
+			name:     "no events without matches",
+			input:    "hello\nworld",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{JSON: true},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err)
+
+			var sb strings.Builder
+			require.NoError(t, m.SearchFile("a.txt", strings.NewReader(tt.input), &sb))
+
+			var lines []string
+			for _, line := range strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n") {
+				if line == "" {
+					continue
+				}
+				require.True(t, json.Valid([]byte(line)), line)
+				lines = append(lines, line)
+			}
+			require.Equal(t, tt.expected, lines)
+		})
+	}
+}
+
+func TestJSONConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		opts domain.GrepOptions
+	}{
+		{name: "count", opts: domain.GrepOptions{JSON: true, Count: true}},
+		{name: "files with matches", opts: domain.GrepOptions{JSON: true, FilesWithMatches: true}},
+		{name: "files without match", opts: domain.GrepOptions{JSON: true, FilesWithoutMatch: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewMatcher("foo", tt.opts)
+			require.ErrorIs(t, err, domain.ErrJSONConflict)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unsafe"
+)
+
+// scanBufSize начальный размер буфера сканера; буфер растёт, если строка в него не помещается
+const scanBufSize = 32 * 1024
+
+// Line структура строки с её содержимым и номером.
+// Строка, полученная от сканера, ссылается на его буфер и действительна до следующего чтения:
+// сохраняемые строки копируются (Line.clone).
+type Line struct {
+	val  string
+	num  int   // номер строки (начиная с 1)
+	off  int64 // смещение начала строки в байтах от начала входа (начиная с 0)
+	skip bool  // в строке нет обязательных литералов паттернов, и она заведомо не совпадает
+}
+
+// lineScanner построчно читает io.Reader, храня в памяти только текущую строку
+// и ещё не разобранные данные последнего чтения. Строки не копируются из буфера,
+// а с префильтром строки без совпадений пропускаются без разбора (ScanCandidate).
+type lineScanner struct {
+	r       io.Reader
+	buf     []byte
+	start   int // начало неразобранных данных buf[start:end]
+	end     int
+	scanned int // конец части буфера, уже проверенной на перевод строки
+	eof     bool
+	line    Line
+	next    int64 // смещение следующей строки
+	err     error
+	lits    *literalFinder // префильтр строк, nil - без префильтра
+}
+
+func newLineScanner(r io.Reader) *lineScanner {
+	return &lineScanner{r: r}
+}
+
+// scanLines создаёт сканер строк r с префильтром по обязательным литералам паттернов
+func (m *Matcher) scanLines(r io.Reader) *lineScanner {
+	sc := newLineScanner(r)
+	if m.literals != nil {
+		sc.lits = newLiteralFinder(m.literals)
+	}
+	return sc
+}
+
+// scanSelected читает следующую строку sc, которая может быть выбрана. Без инверсии (-v)
+// строки, заведомо не содержащие совпадений, пропускаются, кроме keep строк перед кандидатом.
+func (m *Matcher) scanSelected(sc *lineScanner, keep int) bool {
+	if m.opts.InvertMatch {
+		return sc.Scan()
+	}
+	return sc.ScanCandidate(keep)
+}
+
+// Scan читает следующую строку, возвращает false по окончании ввода или при ошибке.
+// Вход дочитывается, только если в буфере нет целой строки.
+func (s *lineScanner) Scan() bool {
+	for {
+		if i := bytes.IndexByte(s.buf[s.scanned:s.end], '\n'); i >= 0 {
+			s.setLine(s.scanned+i, s.scanned+i+1)
+			return true
+		}
+		s.scanned = s.end
+		if s.eof || s.err != nil {
+			// Последняя строка без завершающего перевода строки
+			if s.start == s.end {
+				return false
+			}
+			s.setLine(s.end, s.end)
+			return true
+		}
+		s.fill()
+	}
+}
+
+// ScanCandidate читает следующую строку, которая может содержать совпадение. Строки перед ней,
+// в которых нет обязательных литералов, пропускаются без разбора: буфер просматривается
+// до ближайшего вхождения литерала, и учитываются только число и длина пропущенных строк.
+// Последние keep строк перед кандидатом не пропускаются (контекст -B). Без префильтра
+// ScanCandidate равносилен Scan.
+func (s *lineScanner) ScanCandidate(keep int) bool {
+	if s.lits == nil {
+		return s.Scan()
+	}
+	for {
+		data := s.buf[:s.end]
+		if hit := s.lits.index(data, s.start); hit >= 0 {
+			s.skipTo(s.lineBefore(bytes.LastIndexByte(data[s.start:hit], '\n')+1+s.start, keep))
+			return s.Scan()
+		}
+		// Вхождений нет: пропускаются все целые строки буфера, последняя неполная дочитывается
+		if i := bytes.LastIndexByte(data[s.start:], '\n'); i >= 0 {
+			s.skipTo(s.lineBefore(s.start+i+1, keep))
+		}
+		if s.eof || s.err != nil {
+			return s.Scan()
+		}
+		s.fill()
+	}
+}
+
+// lineBefore возвращает начало keep-й строки перед строкой, начинающейся в pos, но не раньше start
+func (s *lineScanner) lineBefore(pos, keep int) int {
+	for ; keep > 0 && pos > s.start; keep-- {
+		pos = bytes.LastIndexByte(s.buf[s.start:pos-1], '\n') + 1 + s.start
+	}
+	return pos
+}
+
+// skipTo пропускает строки buf[start:pos], учитывая только их число и длину
+func (s *lineScanner) skipTo(pos int) {
+	s.line.num += bytes.Count(s.buf[s.start:pos], []byte{'\n'})
+	s.next += int64(pos - s.start)
+	s.start, s.scanned = pos, max(s.scanned, pos)
+}
+
+// setLine делает текущей строку buf[start:end]; следующая строка начинается с next
+func (s *lineScanner) setLine(end, next int) {
+	s.line = Line{val: bufString(s.buf[s.start:end]), num: s.line.num + 1, off: s.next}
+	if s.lits != nil {
+		s.line.skip = !s.lits.contains(s.buf[:s.end], s.start, end)
+	}
+	s.next += int64(next - s.start)
+	s.start, s.scanned = next, next
+}
+
+// fill дочитывает вход в буфер, предварительно сдвигая неразобранные данные в его начало
+func (s *lineScanner) fill() {
+	if s.start > 0 {
+		s.end = copy(s.buf, s.buf[s.start:s.end])
+		s.scanned -= s.start
+		if s.lits != nil {
+			s.lits.shift(s.start)
+		}
+		s.start = 0
+	}
+	if s.end == len(s.buf) {
+		s.buf = append(s.buf, make([]byte, max(len(s.buf), scanBufSize))...)
+	}
+	n, err := s.r.Read(s.buf[s.end:])
+	s.end += n
+	switch {
+	case err == io.EOF:
+		s.eof = true
+	case err != nil:
+		s.err = err
+	}
+}
+
+// bufString возвращает строку, разделяющую память с b, без копирования
+// Такая строка меняется при следующем чтении и не покидает пакет: в Match.Text
+// и в движки из RegisterEngine передаются копии.
+func bufString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
+}
+
+// clone возвращает строку с копией содержимого, не зависящую от буфера сканера
+func (l Line) clone() Line {
+	l.val = strings.Clone(l.val)
+	return l
+}
+
+// Line возвращает последнюю прочитанную строку
+func (s *lineScanner) Line() Line {
+	return s.line
+}
+
+// Err возвращает первую ошибку чтения, отличную от io.EOF
+func (s *lineScanner) Err() error {
+	return s.err
+}
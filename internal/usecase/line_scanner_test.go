@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func BenchmarkSearch(b *testing.B) {
+	input := benchmarkLogInput()
+	benchmarks := []struct {
+		name    string
+		pattern string
+		opts    domain.GrepOptions
+	}{
+		{name: "literal", pattern: "timeout"},
+		{name: "regex with literal", pattern: "ERROR.*timeout"},
+		{name: "regex without literal", pattern: "[0-9]{4,}$"},
+		{name: "count", pattern: "timeout", opts: domain.GrepOptions{Count: true}},
+		{name: "ignore case", pattern: "TIMEOUT", opts: domain.GrepOptions{IgnoreCase: true}},
+		{name: "invert", pattern: "INFO", opts: domain.GrepOptions{InvertMatch: true}},
+		{name: "context", pattern: "timeout", opts: domain.GrepOptions{AroundContext: true, NumAround: 2}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			m, err := NewMatcher(bm.pattern, bm.opts)
+			require.NoError(b, err)
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for b.Loop() {
+				require.NoError(b, m.Search(strings.NewReader(input), io.Discard))
+			}
+		})
+	}
+}
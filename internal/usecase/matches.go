@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"unix_grep_lite/internal/domain"
+)
+
+// Matches читает строки из r и возвращает выбранные строки, а при флагах -A, -B и -C -
+// и строки контекста, с границами совпадений. Учитываются параметры сопоставления
+// (-i, -v, -w, -x, -F) и ограничение -m; режимы вывода (-c, -l, -L, -q, -o, --json,
+// обработка бинарных входов) на результат не влияют.
+func (m *Matcher) Matches(r io.Reader) ([]domain.Match, error) {
+	var matches []domain.Match
+	for match, err := range m.All(r) {
+		if err != nil {
+			return matches, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// errStopIteration прекращает поиск, когда получатель All больше не запрашивает результаты
+var errStopIteration = errors.New("iteration stopped")
+
+// All лениво перечисляет результаты Matches: строки читаются из r по мере запроса,
+// и при выходе из цикла range чтение прекращается. Ошибка чтения передаётся последней
+// парой с нулевым Match.
+func (m *Matcher) All(r io.Reader) iter.Seq2[domain.Match, error] {
+	return func(yield func(domain.Match, error) bool) {
+		emit := func(match domain.Match) error {
+			if !yield(match, nil) {
+				return errStopIteration
+			}
+			return nil
+		}
+		var err error
+		if m.hasContext() {
+			_, err = m.selectWithContext(r, true, emit)
+		} else {
+			_, err = m.selectWithoutContext(r, true, emit)
+		}
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(domain.Match{}, err)
+		}
+	}
+}
+
+// Count читает строки из r и подсчитывает выбранные с учётом ограничения -m
+func (m *Matcher) Count(r io.Reader) (domain.Count, error) {
+	cnt, err := m.countReader(r)
+	return domain.Count{Lines: cnt}, err
+}
+
+// newMatch формирует результат для строки line; границы совпадений вычисляются,
+// только если они нужны получателю (spans)
+func (m *Matcher) newMatch(line Line, kind domain.MatchKind, spans bool) domain.Match {
+	match := domain.Match{Kind: kind, LineNumber: line.num, Offset: line.off, Text: strings.Clone(line.val)}
+	// Совпадения есть в выбранных строках, а при инверсии (-v) - в контекстных
+	if spans && (kind == domain.KindMatch) != m.opts.InvertMatch {
+		for _, loc := range m.lineMatches(line.val) {
+			match.Submatches = append(match.Submatches, domain.Span{Start: loc[0], End: loc[1]})
+		}
+	}
+	return match
+}
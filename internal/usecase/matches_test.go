@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		pattern  string
+		opts     domain.GrepOptions
+		expected []domain.Match
+	}{
+		{
+			name:    "lines with submatches",
+			input:   "hello\nfoo bar foo\nbar",
+			pattern: "foo",
+			opts:    domain.GrepOptions{},
+			expected: []domain.Match{
+				{LineNumber: 2, Offset: 6, Text: "foo bar foo", Submatches: []domain.Span{{Start: 0, End: 3}, {Start: 8, End: 11}}},
+			},
+		},
+		{
+			name:    "invert match has no submatches",
+			input:   "foo\nbar",
+			pattern: "foo",
+			opts:    domain.GrepOptions{InvertMatch: true},
+			expected: []domain.Match{
+				{LineNumber: 2, Offset: 4, Text: "bar"},
+			},
+		},
+		{
+			name:    "max count",
+			input:   "a1\na2\na3",
+			pattern: "a",
+			opts:    domain.GrepOptions{MaxCount: true, NumMax: 2},
+			expected: []domain.Match{
+				{LineNumber: 1, Offset: 0, Text: "a1", Submatches: []domain.Span{{Start: 0, End: 1}}},
+				{LineNumber: 2, Offset: 3, Text: "a2", Submatches: []domain.Span{{Start: 0, End: 1}}},
+			},
+		},
+		{
+			name:    "context lines",
+			input:   "a\nfoo\nb\nc",
+			pattern: "foo",
+			opts:    domain.GrepOptions{AroundContext: true, NumAround: 1},
+			expected: []domain.Match{
+				{Kind: domain.KindContext, LineNumber: 1, Offset: 0, Text: "a"},
+				{Kind: domain.KindMatch, LineNumber: 2, Offset: 2, Text: "foo", Submatches: []domain.Span{{Start: 0, End: 3}}},
+				{Kind: domain.KindContext, LineNumber: 3, Offset: 6, Text: "b"},
+			},
+		},
+		{
+			name:    "inverted context lines have submatches",
+			input:   "foo\nbar",
+			pattern: "foo",
+			opts:    domain.GrepOptions{InvertMatch: true, BeforeContext: true, NumBefore: 1},
+			expected: []domain.Match{
+				{Kind: domain.KindContext, LineNumber: 1, Offset: 0, Text: "foo", Submatches: []domain.Span{{Start: 0, End: 3}}},
+				{Kind: domain.KindMatch, LineNumber: 2, Offset: 4, Text: "bar"},
+			},
+		},
+		{
+			name:     "output options are ignored",
+			input:    "foo",
+			pattern:  "bar",
+			opts:     domain.GrepOptions{Count: true},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err)
+
+			matches, err := m.Matches(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, matches)
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     domain.GrepOptions
+		expected domain.Count
+	}{
+		{name: "selected lines", input: "foo\nbar\nfoo foo", opts: domain.GrepOptions{}, expected: domain.Count{Lines: 2}},
+		{name: "inverted", input: "foo\nbar\nfoo foo", opts: domain.GrepOptions{InvertMatch: true}, expected: domain.Count{Lines: 1}},
+		{name: "max count", input: "foo\nfoo\nfoo", opts: domain.GrepOptions{MaxCount: true, NumMax: 2}, expected: domain.Count{Lines: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcher("foo", tt.opts)
+			require.NoError(t, err)
+
+			count, err := m.Count(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, count)
+		})
+	}
+}
+
+func TestAllStopsReading(t *testing.T) {
+	// Ошибка чтения после первой строки означает, что поиск продолжился после break
+	readErr := errors.New("read past break")
+
+	for _, opts := range []domain.GrepOptions{
+		{},
+		{AfterContext: true, NumAfter: 5},
+	} {
+		m, err := NewMatcher("x", opts)
+		require.NoError(t, err)
+
+		r := io.MultiReader(strings.NewReader("x\nctx\n"), iotest.ErrReader(readErr))
+		var matches []domain.Match
+		for match, err := range m.All(r) {
+			require.NoError(t, err, "opts=%+v", opts)
+			matches = append(matches, match)
+			break
+		}
+		require.Len(t, matches, 1)
+		require.Equal(t, "x", matches[0].Text)
+	}
+}
+
+func TestAllReadError(t *testing.T) {
+	t.Parallel()
+
+	readErr := errors.New("read failed")
+	m, err := NewMatcher("x", domain.GrepOptions{})
+	require.NoError(t, err)
+
+	r := io.MultiReader(strings.NewReader("x1\ny\nx2\n"), iotest.ErrReader(readErr))
+	var (
+		texts []string
+		errs  []error
+	)
+	for match, err := range m.All(r) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		texts = append(texts, match.Text)
+	}
+	require.Equal(t, []string{"x1", "x2"}, texts)
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], readErr)
+}
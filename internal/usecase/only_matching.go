@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"unix_grep_lite/internal/domain"
+)
+
+// printOnlyMatching выводит каждое совпадение строки на отдельной строке (флаг -o),
+// а при флаге --vimgrep - строку целиком с колонкой каждого совпадения.
+// Как и в GNU grep, контекст не выводится, а строки, выбранные инверсией (-v),
+// не содержат совпадений и поэтому ничего не выводят.
+func (p *printer) printOnlyMatching(match domain.Match) error {
+	for _, s := range match.Submatches {
+		var err error
+		if p.opts.Vimgrep {
+			err = p.printMatchLine(match, s.Start)
+		} else {
+			err = p.printMatch(match, s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printMatchLine выводит строку целиком с колонкой совпадения, начинающегося в start (флаг --vimgrep)
+func (p *printer) printMatchLine(match domain.Match, start int) error {
+	p.buf = p.buf[:0]
+	p.prefix(match, start, match.Offset)
+	p.lineText(match)
+	p.buf = append(p.buf, '\n')
+	return p.write()
+}
+
+// printMatch выводит совпавшую часть строки s с префиксом строки (флаг -o).
+// Смещение -b при этом указывает на начало совпадения.
+func (p *printer) printMatch(match domain.Match, s domain.Span) error {
+	p.buf = p.buf[:0]
+	p.prefix(match, s.Start, match.Offset+int64(s.Start))
+	p.colored(p.opts.Colors.SelectedMatch, match.Text[s.Start:s.End])
+	p.buf = append(p.buf, '\n')
+	return p.write()
+}
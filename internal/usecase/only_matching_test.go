@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOnlyMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		patterns []string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "single match per line",
+			input:    "hello world\nfoo",
+			patterns: []string{"wor"},
+			opts:     domain.GrepOptions{},
+			expected: "wor\n",
+		},
+		{
+			name:     "several matches in one line",
+			input:    "a1b22c333",
+			patterns: []string{`\d+`},
+			opts:     domain.GrepOptions{},
+			expected: "1\n22\n333\n",
+		},
+		{
+			name:     "empty matches are skipped",
+			input:    "abc\nxxb",
+			patterns: []string{"b*"},
+			opts:     domain.GrepOptions{},
+			expected: "b\nb\n",
+		},
+		{
+			name:     "only empty matches",
+			input:    "abc",
+			patterns: []string{"x*"},
+			opts:     domain.GrepOptions{},
+			expected: "",
+		},
+		{
+			name:     "leftmost longest across patterns",
+			input:    "abcd",
+			patterns: []string{"ab", "abc"},
+			opts:     domain.GrepOptions{},
+			expected: "abc\n",
+		},
+		{
+			name:     "fixed strings repeated index",
+			input:    "a.b.c a.b",
+			patterns: []string{"a.b"},
+			opts:     domain.GrepOptions{FixedStrings: true},
+			expected: "a.b\na.b\n",
+		},
+		{
+			name:     "fixed strings longest at same position",
+			input:    "abcd ab",
+			patterns: []string{"ab", "abc", ""},
+			opts:     domain.GrepOptions{FixedStrings: true},
+			expected: "abc\nab\n",
+		},
+		{
+			name:     "fixed strings non-overlapping",
+			input:    "aaaa",
+			patterns: []string{"aa"},
+			opts:     domain.GrepOptions{FixedStrings: true},
+			expected: "aa\naa\n",
+		},
+		{
+			name:     "fixed strings ignore case keeps original text",
+			input:    "Foo FOO foo",
+			patterns: []string{"fOo"},
+			opts:     domain.GrepOptions{FixedStrings: true, IgnoreCase: true},
+			expected: "Foo\nFOO\nfoo\n",
+		},
+		{
+			name:     "fixed strings ignore case with unicode",
+			input:    "Привет ПРИВЕТ",
+			patterns: []string{"привет"},
+			opts:     domain.GrepOptions{FixedStrings: true, IgnoreCase: true},
+			expected: "Привет\nПРИВЕТ\n",
+		},
+		{
+			name:     "fixed strings ignore case with kelvin sign",
+			input:    "\u212Aelvin kELVIN",
+			patterns: []string{"kelvin"},
+			opts:     domain.GrepOptions{FixedStrings: true, IgnoreCase: true},
+			expected: "\u212Aelvin\nkELVIN\n",
+		},
+		{
+			name:     "regex ignore case",
+			input:    "Foo fOO",
+			patterns: []string{"foo"},
+			opts:     domain.GrepOptions{IgnoreCase: true},
+			expected: "Foo\nfOO\n",
+		},
+		{
+			name:     "line numbers and file name",
+			input:    "x\nab ab",
+			patterns: []string{"ab"},
+			opts:     domain.GrepOptions{LineNumber: true, WithFilename: true},
+			expected: "a.txt:2:ab\na.txt:2:ab\n",
+		},
+		{
+			name:     "invert match prints nothing",
+			input:    "foo\nbar",
+			patterns: []string{"foo"},
+			opts:     domain.GrepOptions{InvertMatch: true},
+			expected: "",
+		},
+		{
+			name:     "context is ignored",
+			input:    "1\nfoo\n2",
+			patterns: []string{"foo"},
+			opts:     domain.GrepOptions{AroundContext: true, NumAround: 1},
+			expected: "foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.opts.OnlyMatching = true
+			matcher, err := NewMatcherPatterns(tt.patterns, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			require.NoError(t, matcher.SearchFile("a.txt", strings.NewReader(tt.input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestFoldCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		offsets  []int
+	}{
+		{name: "ascii", input: "HeLLo", expected: "HELLO", offsets: nil},
+		{name: "cyrillic", input: "ПрИ", expected: "ПРИ", offsets: []int{0, 0, 2, 2, 4, 4, 6}},
+		{name: "invalid utf8 is kept", input: "a\xffb", expected: "A\xffB", offsets: []int{0, 1, 2, 3}},
+		{name: "kelvin sign", input: "x\u212A", expected: "XK", offsets: []int{0, 1, 4}},
+		{name: "long s", input: "\u017Fa", expected: "SA", offsets: []int{0, 2, 3}},
+		{name: "final sigma", input: "ςσΣ", expected: "ΣΣΣ", offsets: []int{0, 0, 2, 2, 4, 4, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, offsets := foldCase(tt.input, true)
+			require.Equal(t, tt.expected, result)
+			require.Equal(t, tt.offsets, offsets)
+
+			result, offsets = foldCase(tt.input, false)
+			require.Equal(t, tt.expected, result)
+			require.Nil(t, offsets)
+		})
+	}
+}
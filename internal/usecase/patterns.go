@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"io"
+)
+
+// ReadPatterns читает паттерны из r, по одному на строку (флаг -f).
+// Пустая строка задаёт пустой паттерн, а пустой ввод - пустой набор паттернов.
+func ReadPatterns(r io.Reader) ([]string, error) {
+	var patterns []string
+	sc := newLineScanner(r)
+	for sc.Scan() {
+		patterns = append(patterns, sc.Line().clone().val)
+	}
+	return patterns, sc.Err()
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "empty file has no patterns",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "one pattern per line",
+			input:    "foo\nbar\n",
+			expected: []string{"foo", "bar"},
+		},
+		{
+			name:     "no trailing newline",
+			input:    "foo\nbar",
+			expected: []string{"foo", "bar"},
+		},
+		{
+			name:     "empty line is empty pattern",
+			input:    "foo\n\n",
+			expected: []string{"foo", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			patterns, err := ReadPatterns(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, patterns)
+		})
+	}
+}
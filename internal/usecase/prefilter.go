@@ -0,0 +1,107 @@
+package usecase
+
+import "bytes"
+
+// maxPrefilterLiterals наибольшее число литералов, при котором просмотр буфера
+// отдельно по каждому литералу быстрее проверки строк движком
+const maxPrefilterLiterals = 8
+
+// literalFinder префильтр строк: ищет вхождения обязательных литералов сразу во всём буфере
+// сканера функцией bytes.Index. Строки, в которых нет ни одного литерала, не передаются движку,
+// а без инверсии (-v) пропускаются сканером без разбора на строки.
+type literalFinder struct {
+	lits     [][]byte
+	next     []int // позиция последнего найденного вхождения литерала в буфере, -1 - нет
+	searched []int // конец части буфера, просмотренной при поиске next
+}
+
+// prefilterLiterals возвращает литералы, один из которых содержится в любом совпадении движка,
+// или nil, если префильтр неприменим
+func prefilterLiterals(engine MatchEngine) [][]byte {
+	var lits []string
+	if re, ok := engine.(*regexEngine); ok {
+		lits = re.required
+	} else {
+		// Любое совпадение начинается с одного из префиксов, а значит, содержит его
+		lits = engine.LiteralPrefixes()
+	}
+	if len(lits) == 0 || len(lits) > maxPrefilterLiterals {
+		return nil
+	}
+	out := make([][]byte, 0, len(lits))
+	for _, lit := range lits {
+		if lit == "" {
+			return nil
+		}
+		out = append(out, []byte(lit))
+	}
+	return out
+}
+
+func newLiteralFinder(lits [][]byte) *literalFinder {
+	f := &literalFinder{
+		lits:     lits,
+		next:     make([]int, len(lits)),
+		searched: make([]int, len(lits)),
+	}
+	for i := range f.next {
+		f.next[i] = -1
+	}
+	return f
+}
+
+// find возвращает позицию первого вхождения i-го литерала в buf с позиции from или -1.
+// Позиции from не убывают между вызовами, поэтому найденное вхождение переиспользуется,
+// а после дочитывания буфера просматривается только его новая часть.
+func (f *literalFinder) find(i int, buf []byte, from int) int {
+	if f.next[i] >= from {
+		return f.next[i]
+	}
+	lit := f.lits[i]
+	start := from
+	if f.next[i] < 0 {
+		start = max(from, f.searched[i]-len(lit)+1)
+	}
+	f.next[i] = -1
+	if j := bytes.Index(buf[start:], lit); j >= 0 {
+		f.next[i] = start + j
+	}
+	f.searched[i] = len(buf)
+	return f.next[i]
+}
+
+// index возвращает позицию ближайшего вхождения любого из литералов в buf с позиции from или -1
+func (f *literalFinder) index(buf []byte, from int) int {
+	hit := -1
+	for i := range f.lits {
+		if j := f.find(i, buf, from); j >= 0 && (hit < 0 || j < hit) {
+			hit = j
+		}
+	}
+	return hit
+}
+
+// contains проверяет, есть ли литерал внутри строки buf[start:end]
+func (f *literalFinder) contains(buf []byte, start, end int) bool {
+	for i, lit := range f.lits {
+		if j := f.find(i, buf, start); j >= 0 && j+len(lit) <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// shift учитывает сдвиг данных буфера на n байт к его началу
+func (f *literalFinder) shift(n int) {
+	for i := range f.next {
+		if f.next[i] >= 0 && f.next[i] < n {
+			// Вхождение вне буфера: следующая проверка выполнит поиск заново
+			f.next[i], f.searched[i] = -1, -1
+			continue
+		}
+		if f.next[i] >= 0 {
+			f.next[i] -= n
+		}
+		f.searched[i] -= n
+	}
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// prefilterInput текст, в котором обязательные литералы встречаются и в совпадающих,
+// и в несовпадающих строках, в том числе на стыке строк. После каждой такой строки
+// добавляется fill строк без литералов.
+func prefilterInput(fill int) string {
+	lines := []string{
+		"ERROR: connection timeout",
+		"timeout without level",
+		"ERROR: disk full",
+		"INFO: ok",
+		"ERROR",
+		"timeout",
+		"",
+		"WARN: disk 90% timeout",
+		"GET /api/users 200",
+		"POST /apix 500",
+		"foo_bar foo bar",
+		"ERROR: read timeout",
+	}
+	var sb strings.Builder
+	// Вход больше буфера сканера, чтобы строки пересекали границы чтений
+	for i := 0; sb.Len() < 3*scanBufSize; i++ {
+		sb.WriteString(lines[i%len(lines)])
+		sb.WriteByte('\n')
+		for range fill {
+			sb.WriteString("INFO: request handled\n")
+		}
+	}
+	sb.WriteString("ERROR: last line timeout") // без завершающего перевода строки
+	return sb.String()
+}
+
+func TestPrefilterAgreesWithPlainPath(t *testing.T) {
+	patterns := [][]string{
+		{"ERROR.*timeout"},
+		{"timeout"},
+		{"(?:GET|POST) /api"},
+		{"ERROR", "WARN.*disk"},
+		{"foo"},
+		{"a.b"},
+	}
+	optsSet := []domain.GrepOptions{
+		{},
+		{InvertMatch: true},
+		{WordRegexp: true},
+		{LineRegexp: true},
+		{FixedStrings: true},
+		{AroundContext: true, NumAround: 1},
+		{BeforeContext: true, NumBefore: 2, InvertMatch: true},
+		{BeforeContext: true, NumBefore: 3},
+		{AfterContext: true, NumAfter: 2, MaxCount: true, NumMax: 40},
+		{Count: true},
+		{FilesWithoutMatch: true},
+	}
+	dense, sparse := prefilterInput(0), prefilterInput(150)
+	readers := map[string]func() io.Reader{
+		"dense":          func() io.Reader { return strings.NewReader(dense) },
+		"dense one byte": func() io.Reader { return iotest.OneByteReader(strings.NewReader(dense)) },
+		"sparse":         func() io.Reader { return strings.NewReader(sparse) },
+		"sparse half":    func() io.Reader { return iotest.HalfReader(strings.NewReader(sparse)) },
+	}
+
+	for _, ps := range patterns {
+		for _, opts := range optsSet {
+			m, err := NewMatcherPatterns(ps, opts)
+			require.NoError(t, err)
+			plain := *m
+			plain.literals = nil
+
+			for name, newReader := range readers {
+				msg := fmt.Sprintf("patterns=%q opts=%+v reader=%s", ps, opts, name)
+				want, err := plain.Matches(newReader())
+				require.NoError(t, err, msg)
+				got, err := m.Matches(newReader())
+				require.NoError(t, err, msg)
+				require.Equal(t, want, got, msg)
+
+				wantCount, err := plain.Count(newReader())
+				require.NoError(t, err, msg)
+				gotCount, err := m.Count(newReader())
+				require.NoError(t, err, msg)
+				require.Equal(t, wantCount, gotCount, msg)
+
+				var wantOut, gotOut strings.Builder
+				require.NoError(t, plain.Search(newReader(), &wantOut), msg)
+				require.NoError(t, m.Search(newReader(), &gotOut), msg)
+				require.Equal(t, wantOut.String(), gotOut.String(), msg)
+			}
+		}
+	}
+}
+
+func TestPrefilterLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		opts     domain.GrepOptions
+		expected [][]byte
+	}{
+		{name: "regex required literal", patterns: []string{"ERROR.*timeout"}, expected: [][]byte{[]byte("timeout")}},
+		{name: "regex ignore case", patterns: []string{"ERROR"}, opts: domain.GrepOptions{IgnoreCase: true}, expected: nil},
+		{name: "fixed strings", patterns: []string{"foo", "bar"}, opts: domain.GrepOptions{FixedStrings: true, Engine: EngineFixed}, expected: [][]byte{[]byte("foo"), []byte("bar")}},
+		{name: "fixed empty pattern", patterns: []string{"foo", ""}, opts: domain.GrepOptions{FixedStrings: true, Engine: EngineFixed}, expected: nil},
+		{name: "too many literals", patterns: strings.Split("a b c d e f g h i", " "), opts: domain.GrepOptions{FixedStrings: true}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMatcherPatterns(tt.patterns, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, m.literals)
+		})
+	}
+}
+
+// benchmarkLogInput журнал, в котором совпадает примерно одна строка из ста
+func benchmarkLogInput() string {
+	var sb strings.Builder
+	for i := range 20000 {
+		if i%100 == 0 {
+			fmt.Fprintf(&sb, "2024-05-01T12:00:%02d ERROR request id=%d failed: upstream timeout\n", i%60, i)
+			continue
+		}
+		fmt.Fprintf(&sb, "2024-05-01T12:00:%02d INFO request id=%d handled in %dms\n", i%60, i, i%500)
+	}
+	return sb.String()
+}
+
+func BenchmarkPrefilter(b *testing.B) {
+	input := benchmarkLogInput()
+	for _, pattern := range []string{"ERROR.*timeout", "fail(ed|ure)", "id=[0-9]+ failed"} {
+		m, err := NewMatcher(pattern, domain.GrepOptions{})
+		require.NoError(b, err)
+		plain := *m
+		plain.literals = nil
+
+		for _, bm := range []struct {
+			name    string
+			matcher *Matcher
+		}{{"plain", &plain}, {"prefilter", m}} {
+			b.Run(fmt.Sprintf("%s/%s", pattern, bm.name), func(b *testing.B) {
+				b.SetBytes(int64(len(input)))
+				b.ReportAllocs()
+				for b.Loop() {
+					_, _ = bm.matcher.countReader(strings.NewReader(input))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"unix_grep_lite/internal/domain"
+)
+
+// printer форматирует результаты поиска по одному входу в текст в формате GNU grep
+// и пишет их в буферизованный w
+type printer struct {
+	w       *bufio.Writer
+	groups  groupStarter // получатель начала первой группы контекста, nil - разделитель между входами не нужен
+	opts    domain.GrepOptions
+	name    string // имя входа для префикса (флаги -H/-h)
+	buf     []byte // переиспользуемый буфер для сборки строки вывода
+	lastNum int    // номер последней выведенной строки для разделителей контекста
+	ctxSep  string // разделитель полей префикса строк контекста
+}
+
+// groupStarter вывод нескольких входов, вставляющий разделитель групп контекста
+// между группами разных входов
+type groupStarter interface {
+	// groupStart сообщает, что дальше выводится первая группа строк контекста входа
+	groupStart() error
+}
+
+func newPrinter(w io.Writer, name string, opts domain.GrepOptions) *printer {
+	groups, _ := w.(groupStarter)
+	return &printer{w: bufio.NewWriter(w), groups: groups, opts: opts, name: name, ctxSep: "-"}
+}
+
+// Match выводит строку результата: целиком, по совпадениям (флаги -o и --vimgrep)
+// или с разделителем перед несмежной группой строк контекста
+func (p *printer) Match(match domain.Match) error {
+	if p.opts.OnlyMatching || p.opts.Vimgrep {
+		return p.printOnlyMatching(match)
+	}
+	// Вставка разделителя между несмежными группами строк
+	if p.opts.AfterContext || p.opts.BeforeContext || p.opts.AroundContext {
+		switch {
+		case p.lastNum > 0 && match.LineNumber-p.lastNum > 1:
+			if err := p.printSep(); err != nil {
+				return err
+			}
+		case p.lastNum == 0 && p.groups != nil:
+			// Разделитель перед первой группой входа зависит от вывода предыдущих входов
+			if err := p.Flush(); err != nil {
+				return err
+			}
+			if err := p.groups.groupStart(); err != nil {
+				return &domain.WriteError{Err: err}
+			}
+		}
+		p.lastNum = match.LineNumber
+	}
+	return p.printLine(match)
+}
+
+// colored дописывает в буфер text, обрамлённый SGR-последовательностью sgr, если включена подсветка
+func (p *printer) colored(sgr, text string) {
+	if !p.opts.Color || sgr == "" || text == "" {
+		p.buf = append(p.buf, text...)
+		return
+	}
+	p.buf = append(p.buf, "\033["...)
+	p.buf = append(p.buf, sgr...)
+	p.buf = append(p.buf, 'm')
+	if !p.opts.Colors.NoEraseLine {
+		p.buf = append(p.buf, "\033[K"...)
+	}
+	p.buf = append(p.buf, text...)
+	p.buf = append(p.buf, "\033[m"...)
+	if !p.opts.Colors.NoEraseLine {
+		p.buf = append(p.buf, "\033[K"...)
+	}
+}
+
+// prefix дописывает в буфер имя файла, номер строки, колонку совпадения и смещение
+// согласно флагам -H, -n, --column и -b. Колонка выводится, только если start >= 0.
+// Как и в GNU grep, поля строк контекста отделяются '-', а выбранных строк - ':'.
+func (p *printer) prefix(match domain.Match, start int, off int64) {
+	sep := ":"
+	if match.Kind == domain.KindContext {
+		sep = p.ctxSep
+	}
+	if p.opts.WithFilename {
+		p.colored(p.opts.Colors.FileName, p.name)
+		p.colored(p.opts.Colors.Separator, sep)
+	}
+	if p.opts.LineNumber {
+		p.colored(p.opts.Colors.LineNumber, strconv.Itoa(match.LineNumber))
+		p.colored(p.opts.Colors.Separator, sep)
+	}
+	if p.opts.Column && start >= 0 {
+		p.colored(p.opts.Colors.LineNumber, strconv.Itoa(start+1))
+		p.colored(p.opts.Colors.Separator, sep)
+	}
+	if p.opts.ByteOffset {
+		p.colored(p.opts.Colors.LineNumber, strconv.FormatInt(off, 10))
+		p.colored(p.opts.Colors.Separator, sep)
+	}
+}
+
+// printLine выводит строку с префиксом и колонкой первого совпадения
+func (p *printer) printLine(match domain.Match) error {
+	start := -1
+	if len(match.Submatches) > 0 {
+		start = match.Submatches[0].Start
+	}
+
+	p.buf = p.buf[:0]
+	p.prefix(match, start, match.Offset)
+	p.lineText(match)
+	p.buf = append(p.buf, '\n')
+	return p.write()
+}
+
+// lineText дописывает в буфер текст строки, с подсветкой совпадений при флаге --color
+func (p *printer) lineText(match domain.Match) {
+	if p.opts.Color {
+		p.highlight(match)
+	} else {
+		p.buf = append(p.buf, match.Text...)
+	}
+}
+
+// highlight дописывает в буфер строку с подсветкой совпадений
+func (p *printer) highlight(match domain.Match) {
+	selected := match.Kind == domain.KindMatch
+	colors := p.opts.Colors
+	lineSGR, matchSGR := colors.SelectedLine, colors.SelectedMatch
+	if !selected {
+		lineSGR, matchSGR = colors.ContextLine, colors.ContextMatch
+	}
+	// rv меняет местами цвета выбранных и контекстных строк при -v
+	if colors.Reverse && p.opts.InvertMatch {
+		if selected {
+			lineSGR = colors.ContextLine
+		} else {
+			lineSGR = colors.SelectedLine
+		}
+	}
+
+	val, pos := match.Text, 0
+	for _, s := range match.Submatches {
+		p.colored(lineSGR, val[pos:s.Start])
+		p.colored(matchSGR, val[s.Start:s.End])
+		pos = s.End
+	}
+	p.colored(lineSGR, val[pos:])
+}
+
+// printSep выводит разделитель между несмежными группами строк контекста
+func (p *printer) printSep() error {
+	p.groupSep()
+	return p.write()
+}
+
+// groupSep собирает в буфере и возвращает строку разделителя групп строк контекста
+func (p *printer) groupSep() []byte {
+	p.buf = p.buf[:0]
+	p.colored(p.opts.Colors.Separator, contextSep)
+	p.buf = append(p.buf, '\n')
+	return p.buf
+}
+
+// Count выводит количество совпавших строк (флаг -c), с именем файла при -H
+func (p *printer) Count(count domain.Count) error {
+	p.buf = p.buf[:0]
+	if p.opts.WithFilename {
+		p.colored(p.opts.Colors.FileName, p.name)
+		p.colored(p.opts.Colors.Separator, ":")
+	}
+	p.buf = strconv.AppendInt(p.buf, int64(count.Lines), 10)
+	p.buf = append(p.buf, '\n')
+	return p.write()
+}
+
+// FileName выводит только имя входа (флаги -l и -L)
+func (p *printer) FileName() error {
+	p.buf = p.buf[:0]
+	p.colored(p.opts.Colors.FileName, p.name)
+	p.buf = append(p.buf, '\n')
+	return p.write()
+}
+
+// BinaryMatches выводит сообщение о совпадении в бинарном входе
+func (p *printer) BinaryMatches() error {
+	name := p.name
+	if name == "" {
+		name = defaultLabel
+	}
+	p.buf = append(p.buf[:0], "Binary file "...)
+	p.buf = append(p.buf, name...)
+	p.buf = append(p.buf, " matches\n"...)
+	return p.write()
+}
+
+// End завершает вывод входа; текстовому формату завершающие данные не нужны
+func (p *printer) End() error {
+	return nil
+}
+
+// write выводит собранную в буфере строку
+func (p *printer) write() error {
+	if _, err := p.w.Write(p.buf); err != nil {
+		return &domain.WriteError{Err: err}
+	}
+	return nil
+}
+
+// Flush сбрасывает буферизованный вывод
+func (p *printer) Flush() error {
+	if err := p.w.Flush(); err != nil {
+		return &domain.WriteError{Err: err}
+	}
+	return nil
+}
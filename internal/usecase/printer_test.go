@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrinterPositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		pattern  string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "byte offset of line",
+			input:    "hello\nfoo bar\nbar",
+			pattern:  "bar",
+			opts:     domain.GrepOptions{ByteOffset: true},
+			expected: "6:foo bar\n14:bar\n",
+		},
+		{
+			name:     "byte offset with line numbers",
+			input:    "hello\nfoo bar",
+			pattern:  "bar",
+			opts:     domain.GrepOptions{ByteOffset: true, LineNumber: true},
+			expected: "2:6:foo bar\n",
+		},
+		{
+			name:     "byte offset of match with only matching",
+			input:    "hello\nbar bar",
+			pattern:  "bar",
+			opts:     domain.GrepOptions{ByteOffset: true, OnlyMatching: true},
+			expected: "6:bar\n10:bar\n",
+		},
+		{
+			name:     "byte offset counts multibyte characters",
+			input:    "привет\nмир",
+			pattern:  "мир",
+			opts:     domain.GrepOptions{ByteOffset: true},
+			expected: "13:мир\n",
+		},
+		{
+			name:     "byte offset of context lines",
+			input:    "ab\nfoo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{ByteOffset: true, BeforeContext: true, NumBefore: 1},
+			expected: "0-ab\n3:foo\n",
+		},
+		{
+			name:     "column of first match",
+			input:    "a foo foo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Column: true, LineNumber: true},
+			expected: "1:3:a foo foo\n",
+		},
+		{
+			name:     "column with only matching",
+			input:    "a foo foo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Column: true, OnlyMatching: true},
+			expected: "3:foo\n7:foo\n",
+		},
+		{
+			name:     "column is omitted for lines without match",
+			input:    "ab\nfoo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Column: true, BeforeContext: true, NumBefore: 1},
+			expected: "ab\n1:foo\n",
+		},
+		{
+			name:     "column is omitted for inverted lines",
+			input:    "ab\nfoo",
+			pattern:  "foo",
+			opts:     domain.GrepOptions{Column: true, InvertMatch: true},
+			expected: "ab\n",
+		},
+		{
+			name:    "vimgrep record per match",
+			input:   "x\nfoo a foo",
+			pattern: "foo",
+			opts: domain.GrepOptions{
+				Vimgrep:      true,
+				WithFilename: true,
+				LineNumber:   true,
+				Column:       true,
+			},
+			expected: "a.txt:2:1:foo a foo\na.txt:2:7:foo a foo\n",
+		},
+		{
+			name:    "vimgrep respects max count by lines",
+			input:   "foo foo\nfoo",
+			pattern: "foo",
+			opts: domain.GrepOptions{
+				Vimgrep:      true,
+				WithFilename: true,
+				LineNumber:   true,
+				Column:       true,
+				MaxCount:     true,
+				NumMax:       1,
+			},
+			expected: "a.txt:1:1:foo foo\na.txt:1:5:foo foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			require.NoError(t, matcher.SearchFile("a.txt", strings.NewReader(tt.input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
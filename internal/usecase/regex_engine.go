@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unix_grep_lite/internal/domain"
+)
+
+// matchNothing регулярное выражение, не совпадающее ни с одной строкой
+const matchNothing = `[^\x00-\x{10FFFF}]`
+
+// regexEngine движок регулярных выражений RE2: паттерны объединяются в одну альтернативу,
+// чтобы строка проверялась за один проход
+type regexEngine struct {
+	re       *regexp.Regexp
+	word     bool     // -w без -x: совпадение - первая группа, проверяемая на границы слов
+	prefixes []string // литеральные префиксы паттернов, nil - неизвестны
+	required []string // литералы, один из которых содержит любое совпадение, nil - неизвестны
+}
+
+// newRegexEngine компилирует паттерны; при -F они экранируются и сопоставляются как строки
+func newRegexEngine(patterns []string, opts domain.GrepOptions) (MatchEngine, error) {
+	e := &regexEngine{word: opts.WordRegexp && !opts.LineRegexp}
+
+	alternatives := make([]string, 0, len(patterns))
+	exprs := make([]string, 0, len(patterns))
+	prefixes := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if opts.FixedStrings {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		// Отдельная компиляция даёт понятную ошибку для конкретного паттерна
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, &domain.PatternError{Pattern: pattern, Err: err}
+		}
+		alternatives = append(alternatives, "(?:"+pattern+")")
+		exprs = append(exprs, pattern)
+		prefix, _ := re.LiteralPrefix()
+		prefixes = append(prefixes, prefix)
+	}
+	// Префиксы бесполезны, если хотя бы один паттерн может начинаться с чего угодно,
+	// а при -i совпадение может отличаться от литерала регистром
+	if !opts.IgnoreCase && !slices.Contains(prefixes, "") {
+		e.prefixes = prefixes
+	}
+	// Обязательные литералы для префильтра строк
+	if !opts.IgnoreCase {
+		e.required = requiredLiterals(exprs)
+	}
+
+	regexPattern := strings.Join(alternatives, "|")
+	switch {
+	case len(alternatives) == 0:
+		regexPattern = matchNothing
+	case opts.LineRegexp:
+		regexPattern = "^(?:" + regexPattern + ")$"
+	case opts.WordRegexp:
+		// Совпадение - первая группа, окружённая несловесными символами или границами строки
+		regexPattern = "(?:^|" + nonWordClass + ")(" + regexPattern + ")(?:$|" + nonWordClass + ")"
+	}
+	if opts.IgnoreCase {
+		regexPattern = "(?i)" + regexPattern
+	}
+
+	var err error
+	e.re, err = regexp.Compile(regexPattern)
+	if err != nil {
+		return nil, &domain.PatternError{Pattern: regexPattern, Err: err}
+	}
+	// Как и в GNU grep, из совпадений с одной позиции выбирается самое длинное
+	e.re.Longest()
+	return e, nil
+}
+
+func (e *regexEngine) Match(line string) bool {
+	// Регистр учитывается флагом (?i)
+	return e.re.MatchString(line)
+}
+
+func (e *regexEngine) Find(line string) []int {
+	if e.word {
+		matches := e.wordMatches(line, 1)
+		if len(matches) == 0 {
+			return nil
+		}
+		return matches[0]
+	}
+	loc := e.re.FindStringIndex(line)
+	if loc == nil || loc[0] == loc[1] {
+		// Пустое совпадение может предшествовать непустому
+		if all := e.FindAll(line); len(all) > 0 {
+			return all[0]
+		}
+		return nil
+	}
+	return loc
+}
+
+func (e *regexEngine) FindAll(line string) [][]int {
+	if e.word {
+		return e.wordMatches(line, -1)
+	}
+	matches := e.re.FindAllStringIndex(line, -1)
+	// Пустые совпадения, как и в GNU grep, не выводятся
+	nonEmpty := matches[:0]
+	for _, loc := range matches {
+		if loc[1] > loc[0] {
+			nonEmpty = append(nonEmpty, loc)
+		}
+	}
+	return nonEmpty
+}
+
+func (e *regexEngine) LiteralPrefixes() []string {
+	return e.prefixes
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"regexp/syntax"
+	"slices"
+	"unicode/utf8"
+)
+
+// maxRequiredLiterals наибольшее число литералов в наборе обязательных литералов паттерна
+const maxRequiredLiterals = 8
+
+// requiredLiterals возвращает литералы, хотя бы один из которых содержится в любом совпадении
+// одного из паттернов, или nil, если такой набор неизвестен (например, для ".*" или "a?").
+// Паттерны разбираются так же, как в regexp.Compile.
+func requiredLiterals(patterns []string) []string {
+	if len(patterns) == 0 {
+		return nil
+	}
+	var lits []string
+	for _, pattern := range patterns {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			return nil
+		}
+		set := literalSet(re)
+		if set == nil {
+			return nil
+		}
+		lits = append(lits, set...)
+	}
+	slices.Sort(lits)
+	lits = slices.Compact(lits)
+	if len(lits) > maxRequiredLiterals {
+		return nil
+	}
+	return lits
+}
+
+// literalSet возвращает непустые литералы, один из которых содержится в любом совпадении re, или nil
+func literalSet(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		// При (?i) совпадение может отличаться регистром, а U+FFFD в паттерне
+		// совпадает и с некорректными байтами UTF-8 в строке
+		if re.Flags&syntax.FoldCase != 0 || slices.Contains(re.Rune, utf8.RuneError) {
+			return nil
+		}
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return literalSet(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return nil
+		}
+		return literalSet(re.Sub[0])
+	case syntax.OpConcat:
+		// Достаточно литералов одной части; выбирается набор с самым длинным кратчайшим литералом
+		var best []string
+		for _, sub := range re.Sub {
+			if set := literalSet(sub); set != nil && betterLiteralSet(set, best) {
+				best = set
+			}
+		}
+		return best
+	case syntax.OpAlternate:
+		var set []string
+		for _, sub := range re.Sub {
+			subSet := literalSet(sub)
+			if subSet == nil {
+				return nil
+			}
+			set = append(set, subSet...)
+		}
+		if len(set) > maxRequiredLiterals {
+			return nil
+		}
+		return set
+	default:
+		return nil
+	}
+}
+
+// betterLiteralSet сравнивает наборы литералов: набор с более длинным кратчайшим литералом
+// реже встречается в тексте, а при равенстве предпочтителен меньший набор
+func betterLiteralSet(set, best []string) bool {
+	if best == nil {
+		return true
+	}
+	minLen := func(lits []string) int {
+		n := len(lits[0])
+		for _, lit := range lits[1:] {
+			n = min(n, len(lit))
+		}
+		return n
+	}
+	if a, b := minLen(set), minLen(best); a != b {
+		return a > b
+	}
+	return len(set) < len(best)
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{name: "literal", patterns: []string{"ERROR"}, expected: []string{"ERROR"}},
+		{name: "longest part of concat", patterns: []string{"ERROR.*timeout"}, expected: []string{"timeout"}},
+		{name: "inner literal", patterns: []string{`[0-9]+ms elapsed`}, expected: []string{"ms elapsed"}},
+		{name: "alternation", patterns: []string{"foo|bar"}, expected: []string{"bar", "foo"}},
+		{name: "alternation in concat", patterns: []string{"(?:GET|POST) /api"}, expected: []string{" /api"}},
+		{name: "several patterns", patterns: []string{"ERROR", "WARN.*disk"}, expected: []string{"ERROR", "WARN"}},
+		{name: "plus and repeat", patterns: []string{"(ab)+x{2,}"}, expected: []string{"ab"}},
+		{name: "optional part", patterns: []string{"a?"}, expected: nil},
+		{name: "any char", patterns: []string{".*"}, expected: nil},
+		{name: "one unknown pattern", patterns: []string{"foo", "[a-z]+"}, expected: nil},
+		{name: "case folding", patterns: []string{"(?i)error"}, expected: nil},
+		{name: "replacement character", patterns: []string{"a�b"}, expected: nil},
+		{name: "no patterns", patterns: nil, expected: nil},
+		{name: "too many literals", patterns: []string{"a|b|c|d|e|f|g|h|i"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, requiredLiterals(tt.patterns))
+		})
+	}
+}
@@ -1,45 +1,50 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"io"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 	"unix_grep_lite/internal/domain"
 )
 
-// Matcher структура для поиска с предкомпилированным паттерном
+// Matcher структура для поиска с предкомпилированными паттернами.
+// Сопоставление строк делегируется движку MatchEngine.
 type Matcher struct {
-	pattern       string         // для фиксированных строк
-	compiledRegex *regexp.Regexp // для регулярных выражений
-	opts          domain.GrepOptions
+	engine     MatchEngine
+	literals   [][]byte      // обязательные литералы для префильтра строк, nil - префильтр не используется
+	chunkSize  int64         // размер части входа при параллельном поиске
+	chunkSlots chan struct{} // общий для всех входов бюджет частей, одновременно находящихся в обработке
+	opts       domain.GrepOptions
 }
 
-// NewMatcher создает matcher с с
+// NewMatcher создает matcher для паттерна. Как и в GNU grep, паттерн,
+// содержащий переводы строк, рассматривается как набор паттернов.
 func NewMatcher(pattern string, opts domain.GrepOptions) (*Matcher, error) {
-	m := &Matcher{opts: opts}
-
-	// Обработка фиксированных строк и регулярных выражений
-	if opts.FixedStrings {
-		if opts.IgnoreCase {
-			m.pattern = strings.ToLower(pattern)
-		} else {
-			m.pattern = pattern
-		}
-	} else {
-		regexPattern := pattern
-		if opts.IgnoreCase {
-			regexPattern = "(?i)" + pattern
-		}
+	return NewMatcherPatterns(strings.Split(pattern, "\n"), opts)
+}
 
-		var err error
-		m.compiledRegex, err = regexp.Compile(regexPattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid regexp pattern '%s': %w", pattern, err)
-		}
+// NewMatcherPatterns создает matcher для набора паттернов (флаги -e и -f).
+// Строка выбирается, если совпал хотя бы один паттерн; пустой паттерн
+// совпадает с любой строкой, а пустой набор - ни с одной.
+func NewMatcherPatterns(patterns []string, opts domain.GrepOptions) (*Matcher, error) {
+	if jsonConflicts(opts) {
+		return nil, domain.ErrJSONConflict
 	}
-
-	return m, nil
+	engine, err := newEngine(patterns, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{
+		engine:     engine,
+		literals:   prefilterLiterals(engine),
+		chunkSize:  defaultChunkSize,
+		chunkSlots: make(chan struct{}, chunksInFlight(defaultChunkSize, defaultChunkMemory)),
+		opts:       opts,
+	}, nil
 }
 
 // SearchMatch выполняет поиск паттерна в тексте с заданными опциями
@@ -49,9 +54,21 @@ func (m *Matcher) SearchMatch(pattern, input string, opts domain.GrepOptions) (s
 		result string
 		err    error
 	)
+	// Бинарность определяется по начальному блоку входа, как и при потоковом поиске
+	binary := opts.BinaryFiles != domain.BinaryFilesText &&
+		isBinary(input[:min(len(input), binaryBlockSize)], len(input) > binaryBlockSize)
+	if binary && opts.BinaryFiles == domain.BinaryFilesWithoutMatch {
+		input = ""
+	}
 	switch {
 	case opts.Count:
 		result = strconv.Itoa(m.countOfMatching(input))
+	case binary && input != "":
+		var sb strings.Builder
+		f := newPrinter(&sb, "", opts)
+		_, _ = m.binaryMatches(strings.NewReader(input), f) // strings.Reader/Builder не возвращают ошибок
+		_ = f.Flush()
+		result = strings.TrimSuffix(sb.String(), "\n")
 	case opts.AfterContext || opts.BeforeContext || opts.AroundContext:
 		result, err = m.withContext(input)
 		if err != nil {
@@ -63,13 +80,230 @@ func (m *Matcher) SearchMatch(pattern, input string, opts domain.GrepOptions) (s
 	return result, nil
 }
 
-// lineIsMatch проверяет соответствие строки паттерну
-func (m *Matcher) lineIsMatch(line string) bool {
-	if m.opts.IgnoreCase {
-		line = strings.ToLower(line)
+// Search потоково читает строки из r и пишет результат поиска в w.
+// Память ограничена длиной самой длинной строки и окном контекста.
+func (m *Matcher) Search(r io.Reader, w io.Writer) error {
+	return m.SearchFile("", r, w)
+}
+
+// SearchFile выполняет потоковый поиск как Search, используя name
+// в качестве префикса строк вывода при включённом WithFilename.
+// Ошибки чтения возвращаются как *domain.FileError, ошибки записи - как *domain.WriteError.
+func (m *Matcher) SearchFile(name string, r io.Reader, w io.Writer) error {
+	_, err := m.search(name, r, w)
+	return err
+}
+
+// SearchFormat выполняет потоковый поиск как SearchFile, передавая результаты форматировщику f.
+// Границы совпадений в результатах заполняются всегда.
+func (m *Matcher) SearchFormat(name string, r io.Reader, f Formatter) error {
+	_, err := m.format(name, r, f, true)
+	return err
+}
+
+// search выполняет поиск по одному входу с выводом в формате, выбранном параметрами,
+// и сообщает, была ли выбрана хотя бы одна строка
+func (m *Matcher) search(name string, r io.Reader, w io.Writer) (bool, error) {
+	return m.format(name, r, NewFormatter(w, name, m.opts), m.needSpans())
+}
+
+// format выполняет поиск по одному входу, передавая результаты f, и сообщает,
+// была ли выбрана хотя бы одна строка. spans включает вычисление границ совпадений.
+func (m *Matcher) format(name string, r io.Reader, f Formatter, spans bool) (bool, error) {
+	if m.opts.SearchZip {
+		zr, closeZip, err := decompress(r)
+		if err != nil {
+			return false, domain.NewFileError(name, err)
+		}
+		defer closeZip()
+		r = zr
+	}
+	// Большой файл ищется по частям параллельно; позиция чтения r при этом не используется
+	chunked, parallel := m.parallelInput(r)
+	r, binary, err := m.detectBinary(r)
+	if err != nil {
+		return false, domain.NewFileError(name, err)
+	}
+	parallel = parallel && !binary
+	// При -I бинарный вход обрабатывается как пустой
+	if binary && m.opts.BinaryFiles == domain.BinaryFilesWithoutMatch {
+		r = strings.NewReader("")
+	}
+
+	var selected int
+	switch {
+	case m.opts.Quiet:
+		var found bool
+		found, err = m.hasSelectedLine(r)
+		if found {
+			selected = 1
+		}
+	case m.opts.FilesWithMatches || m.opts.FilesWithoutMatch:
+		var found bool
+		found, err = m.hasSelectedLine(r)
+		// Как и в GNU grep, код возврата зависит от выбранных строк, а не от выведенных имён:
+		// -L, выводящий имя файла без совпадений, завершается с кодом 1
+		if found {
+			selected = 1
+		}
+		if err == nil && found == m.opts.FilesWithMatches {
+			err = f.FileName()
+		}
+	case m.opts.Count:
+		if parallel {
+			selected, err = m.countChunks(chunked)
+		}
+		if !parallel || errors.Is(err, errChunksBusy) {
+			selected, err = m.countReader(r)
+		}
+		if err == nil {
+			err = f.Count(domain.Count{Lines: selected})
+		}
+	case binary && !m.opts.JSON:
+		// В бинарном входе строки не выводятся, как и в GNU grep
+		var found bool
+		found, err = m.binaryMatches(r, f)
+		if found {
+			selected = 1
+		}
+	case !m.opts.JSON && (m.opts.OnlyMatching || m.opts.Vimgrep):
+		// Для -o и --vimgrep контекст не выводится
+		selected, err = m.selectLines(r, chunked, parallel, spans, false, f.Match)
+	case m.hasContext():
+		selected, err = m.selectLines(r, chunked, parallel, spans, true, f.Match)
+		if errors.Is(err, domain.ErrInvalidContextLength) {
+			return false, fmt.Errorf("context processing failed: %w", err)
+		}
+	default:
+		selected, err = m.selectLines(r, chunked, parallel, spans, false, f.Match)
+	}
+	if err == nil {
+		err = f.End()
+	}
+	if flushErr := f.Flush(); err == nil {
+		err = flushErr
+	}
+
+	// Ошибки записи приходят из форматировщика уже обёрнутыми, остальные - ошибки чтения входа
+	var writeErr *domain.WriteError
+	if err != nil && !errors.As(err, &writeErr) {
+		err = domain.NewFileError(name, err)
+	}
+	return selected > 0, err
+}
+
+// selectLines передаёт emit выбранные строки, а при context - и строки контекста,
+// читая r последовательно или, при parallel, вход chunked по частям. Если бюджет частей
+// занят другими входами, r читается последовательно.
+func (m *Matcher) selectLines(r io.Reader, chunked chunkedInput, parallel, spans, context bool, emit func(domain.Match) error) (int, error) {
+	if parallel {
+		cnt, err := m.selectChunks(chunked, spans, context, emit)
+		if !errors.Is(err, errChunksBusy) {
+			return cnt, err
+		}
+	}
+	switch {
+	case context:
+		return m.selectWithContext(r, spans, emit)
+	default:
+		return m.selectWithoutContext(r, spans, emit)
+	}
+}
+
+// needSpans проверяет, нужны ли выводу границы совпадений в строках
+func (m *Matcher) needSpans() bool {
+	o := m.opts
+	return o.Color || o.Column || o.OnlyMatching || o.Vimgrep || o.JSON
+}
+
+// maxCount возвращает ограничение числа выбранных строк (флаг -m) или -1, если его нет
+func (m *Matcher) maxCount() int {
+	if !m.opts.MaxCount || m.opts.NumMax < 0 {
+		return -1
+	}
+	return m.opts.NumMax
+}
+
+// lineIsSelected проверяет, должна ли строка попасть в вывод с учётом инверсии (-v)
+func (m *Matcher) lineIsSelected(line Line) bool {
+	return m.lineIsMatch(line) != m.opts.InvertMatch
+}
+
+// lineIsMatch проверяет соответствие строки паттерну; строки, отсеянные префильтром, движку не передаются
+func (m *Matcher) lineIsMatch(line Line) bool {
+	return !line.skip && m.engine.Match(line.val)
+}
+
+// lineMatches возвращает границы непересекающихся непустых совпадений в строке
+// слева направо; при нескольких совпадениях с одной позиции выбирается самое длинное
+func (m *Matcher) lineMatches(line string) [][]int {
+	return m.engine.FindAll(line)
+}
+
+// foldCase приводит строку к виду, в котором руны, равные без учёта регистра, совпадают:
+// каждая руна заменяется наименьшей руной своего класса unicode.SimpleFold, как при (?i) в RE2.
+// Длина руны при этом может измениться (KELVIN SIGN -> K), поэтому при offsets для строки
+// не из ASCII возвращается также смещение в s каждого байта результата и его конца;
+// nil означает, что смещения совпадают. Некорректные байты UTF-8 остаются как есть.
+func foldCase(s string, offsets bool) (string, []int) {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	i := 0
+	for ; i < len(s) && s[i] < utf8.RuneSelf; i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		sb.WriteByte(c)
+	}
+	if i == len(s) {
+		return sb.String(), nil
+	}
+
+	var offs []int
+	if offsets {
+		offs = make([]int, i, len(s)+1)
+		for j := range offs {
+			offs[j] = j
+		}
+	}
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		n := 1
+		if r == utf8.RuneError && size == 1 {
+			sb.WriteByte(s[i])
+		} else {
+			n, _ = sb.WriteRune(foldRune(r))
+		}
+		if offsets {
+			for range n {
+				offs = append(offs, i)
+			}
+		}
+		i += size
+	}
+	if offsets {
+		offs = append(offs, len(s))
+	}
+	return sb.String(), offs
+}
+
+// foldRune возвращает наименьшую руну из класса рун, равных r без учёта регистра
+func foldRune(r rune) rune {
+	low := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		low = min(low, f)
+	}
+	return low
+}
+
+// unfoldLocs переводит границы совпадений в строке, полученной foldCase, в смещения исходной строки
+func unfoldLocs(locs [][]int, offs []int) [][]int {
+	if offs == nil {
+		return locs
 	}
-	if m.opts.FixedStrings {
-		return strings.Contains(line, m.pattern)
+	for _, loc := range locs {
+		loc[0], loc[1] = offs[loc[0]], offs[loc[1]]
 	}
-	return m.compiledRegex.MatchString(line)
+	return locs
 }
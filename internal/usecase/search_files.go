@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"sync"
+	"unix_grep_lite/internal/domain"
+)
+
+// defaultLabel имя stdin в выводе, если --label не задан
+const defaultLabel = "(standard input)"
+
+// maxPendingOutput наибольший объём вывода по файлу, накапливаемый до его очереди на запись.
+// Поиск в файле, вывод которого больше, приостанавливается, пока не дойдёт его очередь.
+const maxPendingOutput = 256 * 1024
+
+// fileJob файл в очереди поиска, done закрывается по завершении поиска в нём
+type fileJob struct {
+	name    string
+	out     *fileOutput
+	matched bool
+	err     error
+	done    chan struct{}
+}
+
+// fileOutput вывод по файлу: до очереди файла на запись накапливается в буфере
+// не больше maxPendingOutput, а после - пишется сразу в w
+type fileOutput struct {
+	mu      sync.Mutex
+	cond    sync.Cond
+	buf     bytes.Buffer
+	w       io.Writer // nil, пока не дошла очередь файла
+	err     error     // первая ошибка записи в w
+	discard bool      // поиск прерван, вывод не нужен
+	sep     []byte    // разделитель перед первой группой контекста, nil - предыдущие файлы групп не вывели
+	groups  bool      // вывод файла начинается с группы строк контекста
+}
+
+func newFileOutput() *fileOutput {
+	o := &fileOutput{}
+	o.cond.L = &o.mu
+	return o
+}
+
+// Write накапливает p до очереди файла или пишет его в w. Если буфер заполнен,
+// Write ждёт очереди файла; строка длиннее буфера накапливается целиком.
+func (o *fileOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for o.w == nil && !o.discard && o.buf.Len() > 0 && o.buf.Len()+len(p) > maxPendingOutput {
+		o.cond.Wait()
+	}
+	switch {
+	case o.discard:
+		return len(p), nil
+	case o.w == nil:
+		return o.buf.Write(p)
+	case o.err != nil:
+		return 0, o.err
+	}
+	n, err := o.w.Write(p)
+	o.err = err
+	return n, err
+}
+
+// groupStart отмечает начало первой группы контекста файла. Группы выводятся только
+// при контексте -A/-B/-C, когда это начало всего вывода файла, поэтому разделитель
+// после групп предыдущих файлов пишется перед накопленным выводом.
+func (o *fileOutput) groupStart() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.groups = true
+	if o.w == nil || o.discard || o.sep == nil || o.err != nil {
+		return o.err
+	}
+	_, o.err = o.w.Write(o.sep)
+	return o.err
+}
+
+// start пишет накопленный вывод в w, после чего дальнейший вывод пишется в w сразу.
+// sep выводится перед первой группой контекста файла.
+func (o *fileOutput) start(w io.Writer, sep []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.groups && sep != nil {
+		_, o.err = w.Write(sep)
+	}
+	if o.buf.Len() > 0 && o.err == nil {
+		_, o.err = w.Write(o.buf.Bytes())
+	}
+	o.buf = bytes.Buffer{}
+	o.w, o.sep = w, sep
+	o.cond.Broadcast()
+	return o.err
+}
+
+// stop отбрасывает накопленный и дальнейший вывод
+func (o *fileOutput) stop() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.buf = bytes.Buffer{}
+	o.discard = true
+	o.cond.Broadcast()
+}
+
+// SearchFiles ищет совпадения в файлах из files и пишет результаты в w.
+// При threads > 1 файлы обрабатываются параллельно пулом из threads горутин,
+// но вывод по каждому файлу пишется целиком и в порядке files, поэтому
+// результат совпадает с однопоточным. Вывод первого в очереди файла пишется в w сразу,
+// а вывод следующих за ним накапливается не больше maxPendingOutput на файл. Ошибки отдельных файлов и ошибки обхода
+// передаются в onErr в том же порядке и не прерывают поиск, ошибка записи прерывает.
+// Возвращает true, если хотя бы в одном файле выбрана строка; при флаге -q
+// поиск прекращается на первом таком файле.
+func (m *Matcher) SearchFiles(files iter.Seq2[string, error], w io.Writer, threads int, onErr func(error)) bool {
+	matched := false
+	// Группы контекста разных файлов разделяются так же, как группы внутри файла
+	groups, groupSep := false, newPrinter(io.Discard, "", m.opts).groupSep()
+	sep := func() []byte {
+		if groups {
+			return groupSep
+		}
+		return nil
+	}
+	if threads <= 1 {
+		// Однопоточный режим пишет результаты по мере чтения, не накапливая вывод файла
+		for name, err := range files {
+			var fileMatched bool
+			if err == nil {
+				out := newFileOutput()
+				_ = out.start(w, sep()) // накопленного вывода нет
+				fileMatched, err = m.searchPath(name, out)
+				groups = groups || out.groups
+			}
+			matched = matched || fileMatched
+			if err != nil {
+				onErr(err)
+			}
+			if (matched && m.opts.Quiet) || isWriteError(err) {
+				break
+			}
+		}
+		return matched
+	}
+
+	jobs := make(chan *fileJob)
+	// Очередь в порядке files, её ёмкость ограничивает число файлов с накопленным выводом.
+	// Файлы передаются горутинам в том же порядке, поэтому первый в очереди файл всегда
+	// обрабатывается или уже обработан, и ожидание очереди в fileOutput.Write не блокирует поиск.
+	ordered := make(chan *fileJob, threads)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range threads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.matched, job.err = m.searchPath(job.name, job.out)
+				close(job.done)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+		for name, err := range files {
+			job := &fileJob{name: name, out: newFileOutput(), err: err, done: make(chan struct{})}
+			if err != nil {
+				close(job.done)
+			}
+			select {
+			case ordered <- job:
+			case <-stop:
+				return
+			}
+			if err != nil {
+				continue
+			}
+			select {
+			case jobs <- job:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for job := range ordered {
+		writeErr := job.out.start(w, sep())
+		<-job.done
+		groups = groups || job.out.groups
+		err := job.err
+		if writeErr != nil {
+			err = &domain.WriteError{Err: writeErr}
+		}
+		matched = matched || job.matched
+		if err != nil {
+			onErr(err)
+		}
+		// Вывод недоступен или результат -q уже известен, дальнейший поиск бессмысленен
+		if (matched && m.opts.Quiet) || isWriteError(err) {
+			close(stop)
+			// Дожидаемся остановки производителя, не выводя оставшиеся файлы
+			for job := range ordered {
+				job.out.stop()
+			}
+			break
+		}
+	}
+	wg.Wait()
+	return matched
+}
+
+// isWriteError проверяет, является ли err ошибкой записи результатов
+func isWriteError(err error) bool {
+	var writeErr *domain.WriteError
+	return errors.As(err, &writeErr)
+}
+
+// searchPath ищет совпадения в файле name, "-" означает stdin
+func (m *Matcher) searchPath(name string, w io.Writer) (bool, error) {
+	if name == StdinOperand {
+		label := m.opts.Label
+		if label == "" {
+			label = defaultLabel
+		}
+		return m.search(label, os.Stdin, w)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return false, domain.NewFileError(name, err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	return m.search(name, file, w)
+}
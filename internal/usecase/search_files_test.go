@@ -0,0 +1,390 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// errWriter io.Writer, всегда возвращающий ошибку
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+// makeFiles создаёт n файлов с несколькими совпадающими строками и возвращает их пути
+func makeFiles(t *testing.T, n int) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := make([]string, 0, n)
+	for i := range n {
+		path := filepath.Join(dir, fmt.Sprintf("file%03d.txt", i))
+		content := strings.Repeat(fmt.Sprintf("match %d\nskip\n", i), i%7+1)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		files = append(files, path)
+	}
+	return files
+}
+
+func TestSearchFilesOrder(t *testing.T) {
+	t.Parallel()
+
+	files := makeFiles(t, 50)
+	// Несуществующий файл в середине проверяет порядок ошибок относительно вывода
+	files = append(files[:25], append([]string{files[0] + ".missing"}, files[25:]...)...)
+	opts := domain.GrepOptions{WithFilename: true, LineNumber: true}
+
+	matcher, err := NewMatcher("match", opts)
+	require.NoError(t, err, "Failed to create matcher")
+
+	run := func(threads int) (string, []error) {
+		var (
+			out  strings.Builder
+			errs []error
+		)
+		matcher.SearchFiles(WalkFiles(files, opts), &out, threads, func(err error) {
+			errs = append(errs, err)
+		})
+		return out.String(), errs
+	}
+
+	expected, expectedErrs := run(1)
+	require.Len(t, expectedErrs, 1)
+	require.ErrorIs(t, expectedErrs[0], os.ErrNotExist)
+	lines := 0
+	for i := range 50 {
+		lines += i%7 + 1
+	}
+	require.Equal(t, lines, strings.Count(expected, "\n"))
+
+	for _, threads := range []int{2, 4, 16} {
+		out, errs := run(threads)
+		require.Equal(t, expected, out, "threads=%d", threads)
+		require.Equal(t, expectedErrs, errs, "threads=%d", threads)
+	}
+}
+
+func TestSearchFilesWriteError(t *testing.T) {
+	t.Parallel()
+
+	files := makeFiles(t, 20)
+	matcher, err := NewMatcher("match", domain.GrepOptions{})
+	require.NoError(t, err, "Failed to create matcher")
+
+	writeErr := errors.New("write failed")
+	for _, threads := range []int{1, 4} {
+		var errs []error
+		matcher.SearchFiles(WalkFiles(files, domain.GrepOptions{}), errWriter{err: writeErr}, threads, func(err error) {
+			errs = append(errs, err)
+		})
+		require.Len(t, errs, 1, "threads=%d", threads)
+		require.ErrorIs(t, errs[0], writeErr)
+
+		var target *domain.WriteError
+		require.ErrorAs(t, errs[0], &target)
+	}
+}
+
+func TestSearchFilesMatched(t *testing.T) {
+	t.Parallel()
+
+	files := makeFiles(t, 3)
+	missing := files[0] + ".missing"
+
+	tests := []struct {
+		name        string
+		pattern     string
+		files       []string
+		opts        domain.GrepOptions
+		expected    bool
+		expectedOut string
+		expectedErr int
+	}{
+		{
+			name:     "match",
+			pattern:  "match 1",
+			files:    files,
+			expected: true,
+		},
+		{
+			name:     "no match",
+			pattern:  "missing",
+			files:    files,
+			expected: false,
+		},
+		{
+			name:        "error does not hide match",
+			pattern:     "match 2",
+			files:       append([]string{missing}, files...),
+			expected:    true,
+			expectedErr: 1,
+		},
+		{
+			name:     "quiet prints nothing",
+			pattern:  "match",
+			files:    files,
+			opts:     domain.GrepOptions{Quiet: true},
+			expected: true,
+		},
+		{
+			name:     "quiet stops at first matching file",
+			pattern:  "match 0",
+			files:    append(files[:1:1], missing),
+			opts:     domain.GrepOptions{Quiet: true},
+			expected: true,
+		},
+		{
+			name:        "files without match",
+			pattern:     "match 0",
+			files:       files[:2],
+			opts:        domain.GrepOptions{FilesWithoutMatch: true},
+			expected:    true,
+			expectedOut: files[1] + "\n",
+		},
+		{
+			name:        "files without match when nothing matches",
+			pattern:     "missing",
+			files:       files[:2],
+			opts:        domain.GrepOptions{FilesWithoutMatch: true},
+			expected:    false,
+			expectedOut: files[0] + "\n" + files[1] + "\n",
+		},
+		{
+			name:        "files without match when every file matches",
+			pattern:     "match",
+			files:       files[:2],
+			opts:        domain.GrepOptions{FilesWithoutMatch: true},
+			expected:    true,
+			expectedOut: "",
+		},
+		{
+			name:        "files with matches",
+			pattern:     "match 1",
+			files:       files[:2],
+			opts:        domain.GrepOptions{FilesWithMatches: true},
+			expected:    true,
+			expectedOut: files[1] + "\n",
+		},
+		{
+			name:        "files with matches when nothing matches",
+			pattern:     "missing",
+			files:       files[:2],
+			opts:        domain.GrepOptions{FilesWithMatches: true},
+			expected:    false,
+			expectedOut: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			for _, threads := range []int{1, 4} {
+				var (
+					out  strings.Builder
+					errs []error
+				)
+				matched := matcher.SearchFiles(WalkFiles(tt.files, tt.opts), &out, threads, func(err error) {
+					errs = append(errs, err)
+				})
+				require.Equal(t, tt.expected, matched, "threads=%d", threads)
+				require.Len(t, errs, tt.expectedErr, "threads=%d", threads)
+				if tt.opts.Quiet || tt.opts.FilesWithMatches || tt.opts.FilesWithoutMatch {
+					require.Equal(t, tt.expectedOut, out.String(), "threads=%d", threads)
+				}
+				for _, err := range errs {
+					var fileErr *domain.FileError
+					require.ErrorAs(t, err, &fileErr)
+					require.Equal(t, missing, fileErr.Path)
+					require.ErrorIs(t, err, os.ErrNotExist)
+				}
+			}
+		})
+	}
+}
+
+// firstWriteWriter io.Writer, который сообщает размер первой записи и ждёт release
+type firstWriteWriter struct {
+	first   chan int
+	release chan struct{}
+	once    sync.Once
+}
+
+func (w *firstWriteWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		w.first <- len(p)
+		<-w.release
+	})
+	return len(p), nil
+}
+
+func TestSearchFilesStreamsHeadFile(t *testing.T) {
+	t.Parallel()
+
+	// Вывод первого файла намного больше буферов форматировщика и ограничения накопления
+	dir := t.TempDir()
+	head := filepath.Join(dir, "head.txt")
+	content := strings.Repeat("match "+strings.Repeat("x", 100)+"\n", 4*maxPendingOutput/100)
+	require.NoError(t, os.WriteFile(head, []byte(content), 0o644))
+	files := append([]string{head}, makeFiles(t, 10)...)
+
+	matcher, err := NewMatcher("match", domain.GrepOptions{})
+	require.NoError(t, err, "Failed to create matcher")
+
+	w := &firstWriteWriter{first: make(chan int), release: make(chan struct{})}
+	done := make(chan bool)
+	go func() {
+		done <- matcher.SearchFiles(WalkFiles(files, domain.GrepOptions{}), w, 4, func(err error) {
+			t.Error(err)
+		})
+	}()
+
+	// Вывод первого файла начинает писаться до окончания поиска в нём
+	require.Less(t, <-w.first, len(content))
+	close(w.release)
+	require.True(t, <-done)
+}
+
+func TestSearchFilesChunkBudget(t *testing.T) {
+	t.Parallel()
+
+	// Все файлы больше части и ищутся одновременно
+	dir := t.TempDir()
+	files := make([]string, 4)
+	var expected strings.Builder
+	for i := range files {
+		files[i] = filepath.Join(dir, fmt.Sprintf("big%d.txt", i))
+		var content strings.Builder
+		for j := range 2000 {
+			line := fmt.Sprintf("file %d line %d", i, j)
+			if j%3 == 0 {
+				line += " match"
+			}
+			content.WriteString(line + "\n")
+			if j%3 == 0 {
+				fmt.Fprintf(&expected, "%s:%d:%s\n", files[i], j+1, line)
+			}
+		}
+		require.NoError(t, os.WriteFile(files[i], []byte(content.String()), 0o644))
+	}
+
+	opts := domain.GrepOptions{WithFilename: true, LineNumber: true}
+	matcher, err := NewMatcher("match", opts)
+	require.NoError(t, err)
+	matcher.chunkSize = 1024
+	matcher.chunkSlots = make(chan struct{}, 3)
+
+	for _, threads := range []int{1, 4} {
+		var out strings.Builder
+		matched := matcher.SearchFiles(WalkFiles(files, opts), &out, threads, func(err error) {
+			require.NoError(t, err)
+		})
+		require.True(t, matched, "threads=%d", threads)
+		require.Equal(t, expected.String(), out.String(), "threads=%d", threads)
+		require.Empty(t, matcher.chunkSlots, "threads=%d: slots are released", threads)
+	}
+}
+
+func TestFileOutputPendingLimit(t *testing.T) {
+	t.Parallel()
+
+	o := newFileOutput()
+	chunk := []byte(strings.Repeat("x", maxPendingOutput/4))
+	written := make(chan int)
+	go func() {
+		for i := range 8 {
+			_, _ = o.Write(chunk)
+			written <- i
+		}
+		close(written)
+	}()
+
+	// До очереди файла накапливается не больше maxPendingOutput, дальше запись ждёт
+	for i := range 4 {
+		require.Equal(t, i, <-written)
+	}
+	select {
+	case <-written:
+		t.Fatal("pending output exceeds limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	o.mu.Lock()
+	require.LessOrEqual(t, o.buf.Len(), maxPendingOutput)
+	o.mu.Unlock()
+
+	var out strings.Builder
+	require.NoError(t, o.start(&out, nil))
+	for range written {
+	}
+	require.Equal(t, 8*len(chunk), out.Len())
+}
+
+func TestSearchFilesContextSeparator(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var files []string
+	for _, f := range []struct{ name, content string }{
+		{"x", "a\nfoo\nb\nc\nd\nfoo\n"},
+		{"empty", "none\n"},
+		{"y", "foo\nb\n"},
+		{"z", "c\nfoo\n"},
+	} {
+		path := filepath.Join(dir, f.name)
+		require.NoError(t, os.WriteFile(path, []byte(f.content), 0o644))
+		files = append(files, path)
+	}
+	x, y, z := files[0], files[2], files[3]
+
+	tests := []struct {
+		name     string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name: "after context",
+			opts: domain.GrepOptions{WithFilename: true, AfterContext: true, NumAfter: 1},
+			expected: x + ":foo\n" + x + "-b\n--\n" + x + ":foo\n--\n" +
+				y + ":foo\n" + y + "-b\n--\n" + z + ":foo\n",
+		},
+		{
+			name: "before context with line numbers",
+			opts: domain.GrepOptions{WithFilename: true, LineNumber: true, BeforeContext: true, NumBefore: 1},
+			expected: x + "-1-a\n" + x + ":2:foo\n--\n" + x + "-5-d\n" + x + ":6:foo\n--\n" +
+				y + ":1:foo\n--\n" + z + "-1-c\n" + z + ":2:foo\n",
+		},
+		{
+			name:     "no context",
+			opts:     domain.GrepOptions{WithFilename: true},
+			expected: x + ":foo\n" + x + ":foo\n" + y + ":foo\n" + z + ":foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher("foo", tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			for _, threads := range []int{1, 4} {
+				var out strings.Builder
+				matcher.SearchFiles(WalkFiles(files, tt.opts), &out, threads, func(err error) {
+					t.Error(err)
+				})
+				require.Equal(t, tt.expected, out.String(), "threads=%d", threads)
+			}
+		})
+	}
+}
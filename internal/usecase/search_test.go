@@ -1,7 +1,11 @@
 package usecase
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"unix_grep_lite/internal/domain"
 
 	"github.com/stretchr/testify/require"
@@ -149,3 +153,399 @@ func TestSearchMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		input    string
+		opts     domain.GrepOptions
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "plain mode",
+			pattern:  "test",
+			input:    "test\nhello\ntest",
+			opts:     domain.GrepOptions{},
+			expected: "test\ntest\n",
+		},
+		{
+			name:     "no trailing newline in input",
+			pattern:  "world",
+			input:    "hello\nworld",
+			opts:     domain.GrepOptions{LineNumber: true},
+			expected: "2:world\n",
+		},
+		{
+			name:     "trailing newline is not an extra line",
+			pattern:  "^$",
+			input:    "hello\nworld\n",
+			opts:     domain.GrepOptions{Count: true},
+			expected: "0\n",
+		},
+		{
+			name:     "count mode",
+			pattern:  "test",
+			input:    "test\nhello\ntest\n",
+			opts:     domain.GrepOptions{Count: true},
+			expected: "2\n",
+		},
+		{
+			name:     "count empty input",
+			pattern:  "test",
+			input:    "",
+			opts:     domain.GrepOptions{Count: true},
+			expected: "0\n",
+		},
+		{
+			name:    "context with separator",
+			pattern: "x",
+			input:   "a\nx\nb\nc\nd\nx\ne\n",
+			opts: domain.GrepOptions{
+				AroundContext: true,
+				NumAround:     1,
+				LineNumber:    true,
+			},
+			expected: "1-a\n2:x\n3-b\n--\n5-d\n6:x\n7-e\n",
+		},
+		{
+			name:    "before context window slides",
+			pattern: "x",
+			input:   "a\nb\nc\nd\nx\n",
+			opts: domain.GrepOptions{
+				BeforeContext: true,
+				NumBefore:     2,
+			},
+			expected: "c\nd\nx\n",
+		},
+		{
+			name:    "invalid context length",
+			pattern: "x",
+			input:   "x",
+			opts: domain.GrepOptions{
+				BeforeContext: true,
+				NumBefore:     -1,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			err = matcher.Search(strings.NewReader(tt.input), &out)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestSearchReadError(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := NewMatcher("test", domain.GrepOptions{})
+	require.NoError(t, err, "Failed to create matcher")
+
+	readErr := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("test\n"), iotest.ErrReader(readErr))
+
+	var out strings.Builder
+	err = matcher.Search(r, &out)
+	require.ErrorIs(t, err, readErr)
+	require.Equal(t, "test\n", out.String())
+}
+
+func TestSearchFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		input    string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "without filename",
+			pattern:  "test",
+			input:    "test\nhello",
+			opts:     domain.GrepOptions{},
+			expected: "test\n",
+		},
+		{
+			name:     "with filename",
+			pattern:  "test",
+			input:    "test\nhello\ntest",
+			opts:     domain.GrepOptions{WithFilename: true},
+			expected: "a.txt:test\na.txt:test\n",
+		},
+		{
+			name:     "with filename and line numbers",
+			pattern:  "hello",
+			input:    "test\nhello",
+			opts:     domain.GrepOptions{WithFilename: true, LineNumber: true},
+			expected: "a.txt:2:hello\n",
+		},
+		{
+			name:     "count with filename",
+			pattern:  "test",
+			input:    "test\nhello\ntest",
+			opts:     domain.GrepOptions{WithFilename: true, Count: true},
+			expected: "a.txt:2\n",
+		},
+		{
+			name:    "context with filename",
+			pattern: "x",
+			input:   "a\nx\nb\nc\nx",
+			opts: domain.GrepOptions{
+				WithFilename:  true,
+				BeforeContext: true,
+				NumBefore:     1,
+			},
+			expected: "a.txt-a\na.txt:x\n--\na.txt-c\na.txt:x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			err = matcher.SearchFile("a.txt", strings.NewReader(tt.input), &out)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestNewMatcherPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		input    string
+		opts     domain.GrepOptions
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "any pattern matches",
+			patterns: []string{"foo", "bar"},
+			input:    "foo\nbaz\nbar",
+			opts:     domain.GrepOptions{},
+			expected: "foo\nbar\n",
+		},
+		{
+			name:     "regex alternatives are grouped",
+			patterns: []string{"^a", "b$"},
+			input:    "ab\nxb\nax\nxx",
+			opts:     domain.GrepOptions{},
+			expected: "ab\nxb\nax\n",
+		},
+		{
+			name:     "empty pattern matches everything",
+			patterns: []string{"foo", ""},
+			input:    "foo\nbar",
+			opts:     domain.GrepOptions{},
+			expected: "foo\nbar\n",
+		},
+		{
+			name:     "no patterns match nothing",
+			patterns: nil,
+			input:    "foo\nbar",
+			opts:     domain.GrepOptions{},
+			expected: "",
+		},
+		{
+			name:     "no fixed patterns match nothing",
+			patterns: nil,
+			input:    "foo\nbar",
+			opts:     domain.GrepOptions{FixedStrings: true},
+			expected: "",
+		},
+		{
+			name:     "fixed strings ignore case",
+			patterns: []string{"FOO", "b.r"},
+			input:    "foo\nbar\nb.r",
+			opts:     domain.GrepOptions{FixedStrings: true, IgnoreCase: true},
+			expected: "foo\nb.r\n",
+		},
+		{
+			name:     "invert match",
+			patterns: []string{"foo", "bar"},
+			input:    "foo\nbaz\nbar",
+			opts:     domain.GrepOptions{InvertMatch: true},
+			expected: "baz\n",
+		},
+		{
+			name:     "count",
+			patterns: []string{"foo", "bar"},
+			input:    "foo\nbaz\nbar\nfoobar",
+			opts:     domain.GrepOptions{Count: true},
+			expected: "3\n",
+		},
+		{
+			name:     "context",
+			patterns: []string{"foo", "bar"},
+			input:    "foo\n1\n2\n3\nbar",
+			opts:     domain.GrepOptions{AfterContext: true, NumAfter: 1},
+			expected: "foo\n1\n--\nbar\n",
+		},
+		{
+			name:     "invalid pattern among valid",
+			patterns: []string{"foo", "("},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcherPatterns(tt.patterns, tt.opts)
+			if tt.wantErr {
+				var patternErr *domain.PatternError
+				require.ErrorAs(t, err, &patternErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var out strings.Builder
+			require.NoError(t, matcher.Search(strings.NewReader(tt.input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestMaxCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		input    string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "stop after num lines",
+			pattern:  "x",
+			input:    "x1\na\nx2\nx3",
+			opts:     domain.GrepOptions{MaxCount: true, NumMax: 2},
+			expected: "x1\nx2\n",
+		},
+		{
+			name:     "zero prints nothing",
+			pattern:  "x",
+			input:    "x1\nx2",
+			opts:     domain.GrepOptions{MaxCount: true, NumMax: 0},
+			expected: "",
+		},
+		{
+			name:     "negative is unlimited",
+			pattern:  "x",
+			input:    "x1\nx2",
+			opts:     domain.GrepOptions{MaxCount: true, NumMax: -1},
+			expected: "x1\nx2\n",
+		},
+		{
+			name:     "unset zero is unlimited",
+			pattern:  "x",
+			input:    "x1\nx2",
+			opts:     domain.GrepOptions{NumMax: 0},
+			expected: "x1\nx2\n",
+		},
+		{
+			name:     "count is capped",
+			pattern:  "x",
+			input:    "x1\nx2\nx3",
+			opts:     domain.GrepOptions{MaxCount: true, NumMax: 2, Count: true},
+			expected: "2\n",
+		},
+		{
+			name:     "invert counts selected lines",
+			pattern:  "x",
+			input:    "x1\na\nb\nc",
+			opts:     domain.GrepOptions{MaxCount: true, NumMax: 2, InvertMatch: true},
+			expected: "a\nb\n",
+		},
+		{
+			name:     "only matching limits lines not matches",
+			pattern:  "x",
+			input:    "x x\nx",
+			opts:     domain.GrepOptions{MaxCount: true, NumMax: 1, OnlyMatching: true},
+			expected: "x\nx\n",
+		},
+		{
+			name:    "trailing context after last match",
+			pattern: "x",
+			input:   "a\nx1\nx2\nb\nx3\nc",
+			opts: domain.GrepOptions{
+				MaxCount:     true,
+				NumMax:       2,
+				AfterContext: true,
+				NumAfter:     2,
+				LineNumber:   true,
+			},
+			expected: "2:x1\n3:x2\n4-b\n5-x3\n",
+		},
+		{
+			name:    "before context is not printed after limit",
+			pattern: "x",
+			input:   "a\nx1\nb\nx2",
+			opts: domain.GrepOptions{
+				MaxCount:      true,
+				NumMax:        1,
+				BeforeContext: true,
+				NumBefore:     1,
+			},
+			expected: "a\nx1\n",
+		},
+		{
+			name:     "files with matches zero",
+			pattern:  "x",
+			input:    "x",
+			opts:     domain.GrepOptions{MaxCount: true, NumMax: 0, FilesWithMatches: true},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcher(tt.pattern, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			require.NoError(t, matcher.Search(strings.NewReader(tt.input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestMaxCountStopsReading(t *testing.T) {
+	// Ошибка чтения после лимита означает, что поиск дочитал вход до конца
+	readErr := errors.New("read past max count")
+
+	for _, opts := range []domain.GrepOptions{
+		{MaxCount: true, NumMax: 1},
+		{MaxCount: true, NumMax: 1, Count: true},
+		{MaxCount: true, NumMax: 1, OnlyMatching: true},
+		{MaxCount: true, NumMax: 1, AfterContext: true, NumAfter: 1},
+	} {
+		matcher, err := NewMatcher("x", opts)
+		require.NoError(t, err, "Failed to create matcher")
+
+		r := io.MultiReader(strings.NewReader("x\nctx\n"), iotest.ErrReader(readErr))
+		require.NoError(t, matcher.Search(r, io.Discard), "opts=%+v", opts)
+	}
+}
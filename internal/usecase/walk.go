@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unix_grep_lite/internal/domain"
+)
+
+// StdinOperand операнд, обозначающий стандартный ввод
+const StdinOperand = "-"
+
+// WalkFiles возвращает файлы для поиска в порядке операндов и обхода каталогов.
+// Ошибки доступа к отдельным элементам передаются как *domain.FileError вместе
+// с пустым путём, после чего обход продолжается. Без операндов при рекурсивном поиске
+// обходится текущий каталог, иначе читается stdin. Внутри каталогов пропускаются
+// скрытые элементы (кроме --hidden) и пути, исключённые правилами .gitignore, .ignore
+// и .git/info/exclude обходимых каталогов, а внутри git-репозитория - и каталогов
+// между операндом и корнем репозитория (кроме --no-ignore); операнды не фильтруются.
+// Фильтры --include, --exclude, -t и -T применяются ко всем файлам, --exclude-dir -
+// к вложенным каталогам.
+func WalkFiles(operands []string, opts domain.GrepOptions) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		w := &walker{opts: opts, yield: yield}
+		if len(operands) == 0 {
+			if opts.Recursive {
+				w.implicitRoot()
+			} else {
+				yield(StdinOperand, nil)
+			}
+			return
+		}
+		for _, path := range operands {
+			if !w.operand(path) {
+				return
+			}
+		}
+	}
+}
+
+// walker состояние обхода каталогов
+type walker struct {
+	opts      domain.GrepOptions
+	yield     func(string, error) bool
+	ancestors []fs.FileInfo // каталоги текущей ветки обхода для обнаружения циклов
+	names     []string      // имена каталогов текущей ветки относительно операнда
+	ignores   []ignoreLevel // правила исключения каталогов текущей ветки
+}
+
+// ignoreLevel правила исключения каталога, находящегося на глубине depth ветки обхода.
+// Для каталогов выше операнда prefix - путь от каталога правил до операнда.
+type ignoreLevel struct {
+	rules  ignoreRules
+	depth  int
+	prefix string
+}
+
+// operand обрабатывает операнд командной строки.
+// Символические ссылки в операндах разыменовываются и для -r, и для -R.
+func (w *walker) operand(path string) bool {
+	if path == StdinOperand {
+		return w.yield(path, nil)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return w.yield("", domain.NewFileError(path, err))
+	}
+	switch {
+	case info.IsDir():
+		if !w.opts.Recursive {
+			return w.yield("", domain.NewFileError(path, domain.ErrIsDirectory))
+		}
+		return w.root(path, path, info)
+	case isSpecialFile(info) && w.opts.SkipDevices:
+		return true
+	case w.fileFiltered(path):
+		return true
+	default:
+		return w.yield(path, nil)
+	}
+}
+
+// implicitRoot обходит текущий каталог, выводя пути без префикса "./"
+func (w *walker) implicitRoot() {
+	info, err := os.Stat(".")
+	if err != nil {
+		w.yield("", domain.NewFileError(".", err))
+		return
+	}
+	w.root(".", "", info)
+}
+
+// root обходит каталог-операнд path с правилами исключения каталогов над ним
+func (w *walker) root(path, prefix string, info fs.FileInfo) bool {
+	if !w.opts.NoIgnore {
+		w.ignores = ancestorIgnores(path)
+		defer func() { w.ignores = nil }()
+	}
+	return w.dir(path, prefix, info, 0)
+}
+
+// ancestorIgnores возвращает правила исключения каталогов над каталогом path до корня
+// git-репозитория (каталога, содержащего .git) включительно, начиная с корня.
+// Вне репозитория правила внешних каталогов не действуют.
+func ancestorIgnores(path string) []ignoreLevel {
+	abs, err := filepath.Abs(path)
+	if err != nil || isRepoRoot(abs) {
+		return nil
+	}
+	var dirs []string
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if isRepoRoot(dir) {
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			return nil
+		}
+	}
+
+	var levels []ignoreLevel
+	for _, dir := range slices.Backward(dirs) {
+		rules := readIgnoreDir(dir)
+		if len(rules) == 0 {
+			continue
+		}
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil {
+			continue
+		}
+		levels = append(levels, ignoreLevel{rules: rules, prefix: filepath.ToSlash(rel)})
+	}
+	return levels
+}
+
+// dir рекурсивно обходит каталог path; prefix - префикс путей вложенных элементов.
+// Возвращает false, если получатель прекратил обход.
+func (w *walker) dir(path, prefix string, info fs.FileInfo, depth int) bool {
+	for _, a := range w.ancestors {
+		if os.SameFile(a, info) {
+			return w.yield("", domain.NewFileError(path, domain.ErrRecursiveLoop))
+		}
+	}
+	if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+		return true
+	}
+
+	// os.ReadDir возвращает элементы, отсортированные по имени, что даёт детерминированный порядок
+	entries, err := os.ReadDir(path)
+	if err != nil && !w.yield("", domain.NewFileError(path, err)) {
+		return false
+	}
+
+	w.ancestors = append(w.ancestors, info)
+	defer func() { w.ancestors = w.ancestors[:len(w.ancestors)-1] }()
+	if !w.opts.NoIgnore {
+		if rules := readIgnoreDir(path); len(rules) > 0 {
+			w.ignores = append(w.ignores, ignoreLevel{rules: rules, depth: len(w.names)})
+			defer func() { w.ignores = w.ignores[:len(w.ignores)-1] }()
+		}
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !w.opts.Hidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		// Служебный каталог git не обходится даже с --hidden, пока правила игнорирования включены
+		if !w.opts.NoIgnore && name == gitDir {
+			continue
+		}
+		child := joinPath(prefix, name)
+
+		var childInfo fs.FileInfo
+		if e.Type()&fs.ModeSymlink != 0 {
+			// -r не переходит по символическим ссылкам внутри каталогов
+			if !w.opts.Dereference {
+				continue
+			}
+			childInfo, err = os.Stat(child)
+		} else {
+			childInfo, err = e.Info()
+		}
+		if err != nil {
+			if !w.yield("", domain.NewFileError(child, err)) {
+				return false
+			}
+			continue
+		}
+
+		if w.ignored(name, childInfo.IsDir()) {
+			continue
+		}
+		if childInfo.IsDir() && w.dirFiltered(name) || childInfo.Mode().IsRegular() && w.fileFiltered(child) {
+			continue
+		}
+
+		switch {
+		case childInfo.IsDir():
+			w.names = append(w.names, name)
+			ok := w.dir(child, child, childInfo, depth+1)
+			w.names = w.names[:len(w.names)-1]
+			if !ok {
+				return false
+			}
+		case childInfo.Mode().IsRegular():
+			if !w.yield(child, nil) {
+				return false
+			}
+		default:
+			// Устройства, FIFO и сокеты при рекурсивном обходе пропускаются
+		}
+	}
+	return true
+}
+
+// ignored проверяет, исключён ли элемент name текущего каталога. Правила более глубоких
+// каталогов имеют приоритет; исключённые каталоги не обходятся, поэтому вложенные
+// в них пути, как и в git, не могут быть возвращены отрицанием.
+func (w *walker) ignored(name string, isDir bool) bool {
+	for i := len(w.ignores) - 1; i >= 0; i-- {
+		level := w.ignores[i]
+		rel := strings.Join(append(w.names[level.depth:len(w.names):len(w.names)], name), "/")
+		if level.prefix != "" {
+			rel = level.prefix + "/" + rel
+		}
+		if ignored, ok := level.rules.match(rel, isDir); ok {
+			return ignored
+		}
+	}
+	return false
+}
+
+// joinPath объединяет каталог и имя без очистки пути, как это делает GNU grep
+func joinPath(dir, name string) string {
+	switch {
+	case dir == "":
+		return name
+	case strings.HasSuffix(dir, "/"):
+		return dir + name
+	default:
+		return dir + "/" + name
+	}
+}
+
+// isSpecialFile проверяет, является ли файл устройством, FIFO или сокетом
+func isSpecialFile(info fs.FileInfo) bool {
+	return info.Mode()&(fs.ModeDevice|fs.ModeCharDevice|fs.ModeNamedPipe|fs.ModeSocket) != 0
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// makeTree создаёт дерево файлов для тестов обхода:
+//
+//	root/a/x, root/a/b/y, root/top, root/c/link -> ../a, root/a/b/up -> .. (т.е. root/a)
+func makeTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "a", "b"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "c"), 0o755))
+	for _, name := range []string{"a/x", "a/b/y", "top"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte("foo\n"), 0o644))
+	}
+	require.NoError(t, os.Symlink("../a", filepath.Join(root, "c", "link")))
+	require.NoError(t, os.Symlink("..", filepath.Join(root, "a", "b", "up")))
+	return root
+}
+
+// collectWalk собирает пути и ошибки обхода
+func collectWalk(operands []string, opts domain.GrepOptions) ([]string, []error) {
+	var (
+		files []string
+		errs  []error
+	)
+	for path, err := range WalkFiles(operands, opts) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files = append(files, path)
+	}
+	return files, errs
+}
+
+func TestWalkFiles(t *testing.T) {
+	root := makeTree(t)
+
+	tests := []struct {
+		name        string
+		operands    []string
+		opts        domain.GrepOptions
+		expected    []string
+		expectedErr []error
+	}{
+		{
+			name:     "no operands reads stdin",
+			operands: nil,
+			opts:     domain.GrepOptions{},
+			expected: []string{"-"},
+		},
+		{
+			name:     "file operands keep order",
+			operands: []string{root + "/top", "-", root + "/a/x"},
+			opts:     domain.GrepOptions{},
+			expected: []string{root + "/top", "-", root + "/a/x"},
+		},
+		{
+			name:        "directory without recursion",
+			operands:    []string{root + "/a"},
+			opts:        domain.GrepOptions{},
+			expectedErr: []error{domain.ErrIsDirectory},
+		},
+		{
+			name:     "recursive skips symlinks",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true},
+			expected: []string{root + "/a/b/y", root + "/a/x", root + "/top"},
+		},
+		{
+			name:     "recursive follows symlink operand",
+			operands: []string{root + "/c/link"},
+			opts:     domain.GrepOptions{Recursive: true},
+			expected: []string{root + "/c/link/b/y", root + "/c/link/x"},
+		},
+		{
+			name:        "dereference with loop detection",
+			operands:    []string{root + "/a"},
+			opts:        domain.GrepOptions{Recursive: true, Dereference: true},
+			expected:    []string{root + "/a/b/y", root + "/a/x"},
+			expectedErr: []error{domain.ErrRecursiveLoop},
+		},
+		{
+			name:     "max depth",
+			operands: []string{root},
+			opts:     domain.GrepOptions{Recursive: true, MaxDepth: 1},
+			expected: []string{root + "/top"},
+		},
+		{
+			name:        "missing operand does not stop walk",
+			operands:    []string{root + "/missing", root + "/top"},
+			opts:        domain.GrepOptions{},
+			expected:    []string{root + "/top"},
+			expectedErr: []error{os.ErrNotExist},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, errs := collectWalk(tt.operands, tt.opts)
+			require.Equal(t, tt.expected, files)
+			require.Len(t, errs, len(tt.expectedErr))
+			for i, err := range errs {
+				require.ErrorIs(t, err, tt.expectedErr[i])
+			}
+		})
+	}
+}
+
+func TestWalkFilesImplicitRoot(t *testing.T) {
+	t.Chdir(makeTree(t))
+
+	files, errs := collectWalk(nil, domain.GrepOptions{Recursive: true})
+	require.Empty(t, errs)
+	require.Equal(t, []string{"a/b/y", "a/x", "top"}, files)
+}
+
+func TestWalkFilesSkipDevices(t *testing.T) {
+	t.Parallel()
+
+	// /dev/null - символьное устройство, доступное на любой Unix-системе
+	files, errs := collectWalk([]string{os.DevNull}, domain.GrepOptions{})
+	require.Empty(t, errs)
+	require.Equal(t, []string{os.DevNull}, files)
+
+	files, errs = collectWalk([]string{os.DevNull}, domain.GrepOptions{SkipDevices: true})
+	require.Empty(t, errs)
+	require.Empty(t, files)
+}
+
+func TestWalkFilesStopEarly(t *testing.T) {
+	t.Parallel()
+
+	root := makeTree(t)
+	var files []string
+	for path, err := range WalkFiles([]string{root}, domain.GrepOptions{Recursive: true}) {
+		require.NoError(t, err)
+		files = append(files, path)
+		break
+	}
+	require.Equal(t, []string{root + "/a/b/y"}, files)
+}
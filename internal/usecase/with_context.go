@@ -1,90 +1,107 @@
 package usecase
 
 import (
-	"strconv"
+	"io"
 	"strings"
 	"unix_grep_lite/internal/domain"
 )
 
-// Line структура строки с её содержимым и номером
-type Line struct {
-	val string
-	num int // номер строки (начиная с 1)
-}
+// contextSep разделитель между несмежными группами строк
+const contextSep = "--"
 
 // withContext обрабатывает поиск с контекстом (строки до/после совпадений)
 func (m *Matcher) withContext(input string) (string, error) {
-	if input == "" {
-		return "", nil
+	var sb strings.Builder
+	f := newPrinter(&sb, "", m.opts)
+	f.ctxSep = ":" // SearchMatch сохраняет исходный формат, в котором поля всех строк отделяются ':'
+	r := strings.NewReader(input)
+	chunked, parallel := m.parallelInput(r)
+	if _, err := m.selectLines(r, chunked, parallel, false, true, f.Match); err != nil {
+		return "", err
 	}
+	_ = f.Flush() // strings.Builder не возвращает ошибок
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
 
-	if m.opts.NumAfter < 0 || m.opts.NumBefore < 0 || m.opts.NumAround < 0 {
-		return "", domain.ErrInvalidContextLength
+// selectWithContext потоково передаёт emit выбранные строки из r вместе с контекстом.
+// В памяти хранится не более NumBefore предшествующих строк. При флаге -m после NumMax
+// выбранных строк передаётся только их завершающий контекст -A, после чего чтение прекращается.
+// Возвращает число выбранных строк.
+func (m *Matcher) selectWithContext(r io.Reader, spans bool, emit func(domain.Match) error) (int, error) {
+	beforeN, afterN, err := m.contextLengths()
+	if err != nil {
+		return 0, err
 	}
 
-	// Преобразование -C в -A и -B, т.к. -AB 1 ~ -C 1
-	if m.opts.AroundContext {
-		m.opts.BeforeContext, m.opts.AfterContext = true, true
-		m.opts.NumBefore, m.opts.NumAfter = m.opts.NumAround, m.opts.NumAround
+	before := make([]Line, 0, beforeN) // последние несовпавшие строки для -B
+	afterLeft := 0                     // сколько строк осталось передать для -A
+	cnt, limit := 0, m.maxCount()      // число выбранных строк и ограничение -m
+	context := func(line Line) error {
+		return emit(m.newMatch(line, domain.KindContext, spans))
 	}
 
-	beforeN, afterN := m.opts.NumBefore, m.opts.NumAfter
-	seens := make(map[int]bool)     // предотвращает дублирование строк
-	matchedLines := make([]Line, 0) // результирующие строки с номерами
-	lines := strings.Split(input, "\n")
-	for i, line := range lines {
-		isMatch := m.lineIsMatch(line)
-		// Инверсия результата для флага -v
-		if m.opts.InvertMatch {
-			isMatch = !isMatch
+	sc := m.scanLines(r)
+	// Строки после совпадения выводятся как контекст -A и не пропускаются
+	next := func() bool {
+		if afterLeft > 0 {
+			return sc.Scan()
 		}
-		if isMatch {
-			// Добавление контекстных строк до совпадения
-			if m.opts.BeforeContext {
-				for j := max(i-beforeN, 0); j < i; j++ {
-					if seens[j] {
-						continue
-					}
-					seens[j] = true
-					matchedLines = append(matchedLines, Line{val: lines[j], num: j + 1})
+		return m.scanSelected(sc, beforeN)
+	}
+	for (cnt != limit || afterLeft > 0) && next() {
+		line := sc.Line()
+		switch {
+		case cnt != limit && m.lineIsSelected(line):
+			cnt++
+			// Вывод накопленных контекстных строк до совпадения
+			for _, b := range before {
+				if err := context(b); err != nil {
+					return cnt, err
 				}
 			}
-			// Добавление самой совпавшей строки
-			if !seens[i] {
-				matchedLines = append(matchedLines, Line{val: lines[i], num: i + 1})
-				seens[i] = true
+			before = before[:0]
+			if err := emit(m.newMatch(line, domain.KindMatch, spans)); err != nil {
+				return cnt, err
 			}
-			// Добавление контекстных строк после совпадения
-			if m.opts.AfterContext {
-				for j := i + 1; j <= min(i+afterN, len(lines)-1); j++ {
-					if seens[j] {
-						continue
-					}
-					seens[j] = true
-					matchedLines = append(matchedLines, Line{val: lines[j], num: j + 1})
-				}
+			afterLeft = afterN
+		case afterLeft > 0:
+			// Контекстная строка после совпадения
+			if err := context(line); err != nil {
+				return cnt, err
 			}
+			afterLeft--
+		case beforeN > 0 && cnt != limit:
+			// Скользящее окно последних строк для -B
+			if len(before) == beforeN {
+				copy(before, before[1:])
+				before = before[:beforeN-1]
+			}
+			before = append(before, line.clone())
 		}
 	}
+	return cnt, sc.Err()
+}
 
-	result := joinLinesContextsWithSep(matchedLines, "--", m.opts.LineNumber)
-	return strings.Join(result, "\n"), nil
+// hasContext проверяет, задан ли вывод контекста флагами -A, -B или -C
+func (m *Matcher) hasContext() bool {
+	return m.opts.AfterContext || m.opts.BeforeContext || m.opts.AroundContext
 }
 
-// joinLinesContextsWithSep объединяет строки с разделителем между группами
-func joinLinesContextsWithSep(matchedLines []Line, sep string, isLineNumber bool) []string {
-	result := make([]string, 0, len(matchedLines))
-	for i := 0; i < len(matchedLines); i++ {
-		// Добавление номера строки для флага -n
-		if isLineNumber {
-			result = append(result, strconv.Itoa(matchedLines[i].num)+":"+matchedLines[i].val)
-		} else {
-			result = append(result, matchedLines[i].val)
-		}
-		// Вставка разделителя между несмежными группами строк
-		if i+1 < len(matchedLines) && matchedLines[i+1].num-matchedLines[i].num > 1 {
-			result = append(result, sep)
-		}
+// contextLengths возвращает длины контекста до и после совпадения с учётом флагов -A, -B и -C
+func (m *Matcher) contextLengths() (beforeN, afterN int, err error) {
+	if m.opts.NumAfter < 0 || m.opts.NumBefore < 0 || m.opts.NumAround < 0 {
+		return 0, 0, domain.ErrInvalidContextLength
+	}
+
+	// Преобразование -C в -A и -B, т.к. -AB 1 ~ -C 1
+	if m.opts.AroundContext {
+		return m.opts.NumAround, m.opts.NumAround, nil
+	}
+	if m.opts.BeforeContext {
+		beforeN = m.opts.NumBefore
+	}
+	if m.opts.AfterContext {
+		afterN = m.opts.NumAfter
 	}
-	return result
+	return beforeN, afterN, nil
 }
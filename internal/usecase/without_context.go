@@ -1,32 +1,36 @@
 package usecase
 
 import (
-	"strconv"
+	"io"
 	"strings"
+	"unix_grep_lite/internal/domain"
 )
 
 // withoutContext выполняет базовый поиск без контекста (только совпавшие строки)
 func (m *Matcher) withoutContext(input string) string {
-	if input == "" {
-		return ""
-	}
+	var sb strings.Builder
+	f := newPrinter(&sb, "", m.opts)
+	r := strings.NewReader(input)
+	chunked, parallel := m.parallelInput(r)
+	_, _ = m.selectLines(r, chunked, parallel, false, false, f.Match) // strings.Reader/Builder не возвращают ошибок
+	_ = f.Flush()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
 
-	lines := strings.Split(input, "\n")
-	matchedLines := []string{}
-	for i, line := range lines {
-		isMatch := m.lineIsMatch(line)
-		// Инверсия результата для флага -v
-		if m.opts.InvertMatch {
-			isMatch = !isMatch
-		}
-		if isMatch {
-			// Добавление номера строки для флага -n
-			if m.opts.LineNumber {
-				matchedLines = append(matchedLines, strconv.Itoa(i+1)+":"+line)
-			} else {
-				matchedLines = append(matchedLines, line)
+// selectWithoutContext потоково передаёт emit выбранные строки из r.
+// При флаге -m чтение прекращается после NumMax выбранных строк.
+// Возвращает число выбранных строк.
+func (m *Matcher) selectWithoutContext(r io.Reader, spans bool, emit func(domain.Match) error) (int, error) {
+	cnt, limit := 0, m.maxCount()
+	sc := m.scanLines(r)
+	for cnt != limit && m.scanSelected(sc, 0) {
+		line := sc.Line()
+		if m.lineIsSelected(line) {
+			if err := emit(m.newMatch(line, domain.KindMatch, spans)); err != nil {
+				return cnt, err
 			}
+			cnt++
 		}
 	}
-	return strings.Join(matchedLines, "\n")
+	return cnt, sc.Err()
 }
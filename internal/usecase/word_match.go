@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// nonWordClass класс несловесных символов: словесными считаются буквы, цифры и '_'
+const nonWordClass = `[^\p{L}\p{N}_]`
+
+// index возвращает позицию первого с pos вхождения фиксированной строки pattern,
+// удовлетворяющего флагам -x и -w, или -1. Если вхождение не ограничено несловесными
+// символами, поиск, как и в GNU grep, продолжается со следующей позиции.
+func (e *fixedEngine) index(line, pattern string, pos int) int {
+	if e.line {
+		if pos == 0 && line == pattern {
+			return 0
+		}
+		return -1
+	}
+
+	for pos <= len(line)-len(pattern) {
+		i := strings.Index(line[pos:], pattern)
+		if i < 0 {
+			return -1
+		}
+		i += pos
+		if !e.word || isWordBounded(line, i, i+len(pattern)) {
+			return i
+		}
+		pos = i + nextRuneLen(line[i:])
+	}
+	return -1
+}
+
+// wordMatches возвращает не более n (n < 0 - все) совпадений регулярного выражения для флага -w.
+// Регулярное выражение поглощает ограничивающие символы, поэтому поиск следующего
+// совпадения начинается сразу после предыдущего, а не после его правой границы.
+func (e *regexEngine) wordMatches(line string, n int) [][]int {
+	var matches [][]int
+	for pos := 0; pos <= len(line) && len(matches) != n; {
+		loc := e.re.FindStringSubmatchIndex(line[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := loc[2]+pos, loc[3]+pos
+		// Начало подстроки совпадает с началом строки только при pos == 0,
+		// поэтому левую границу совпадения в начале подстроки проверяем по всей строке
+		if end == start || !isWordBounded(line, start, end) {
+			pos = start + nextRuneLen(line[start:])
+			continue
+		}
+		matches = append(matches, []int{start, end})
+		pos = end
+	}
+	return matches
+}
+
+// isWordBounded проверяет, что line[start:end] ограничена несловесными символами или границами строки
+func isWordBounded(line string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(line[:start]); isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(line) {
+		if r, _ := utf8.DecodeRuneInString(line[end:]); isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune проверяет, является ли руна словесным символом
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// nextRuneLen возвращает длину первой руны s, но не меньше 1 байта
+func nextRuneLen(s string) int {
+	_, size := utf8.DecodeRuneInString(s)
+	return max(size, 1)
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unix_grep_lite/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordAndLineRegexp(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		patterns []string
+		opts     domain.GrepOptions
+		expected string
+	}{
+		{
+			name:     "word regexp skips substring of word",
+			input:    "valid\nid\nmy id here\nid_x\nid-x",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{WordRegexp: true},
+			expected: "id\nmy id here\nid-x\n",
+		},
+		{
+			name:     "word regexp retries later match on same line",
+			input:    "valid id",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{WordRegexp: true, OnlyMatching: true},
+			expected: "id\n",
+		},
+		{
+			name:     "word regexp tries shorter match at same start",
+			input:    "foobarx foo",
+			patterns: []string{"foo|foobar"},
+			opts:     domain.GrepOptions{WordRegexp: true, OnlyMatching: true},
+			expected: "foo\n",
+		},
+		{
+			name:     "word regexp adjacent words",
+			input:    "id id,id",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{WordRegexp: true, OnlyMatching: true},
+			expected: "id\nid\nid\n",
+		},
+		{
+			name:     "word regexp with non-word pattern edge",
+			input:    "foo-bar",
+			patterns: []string{"foo", "-bar"},
+			opts:     domain.GrepOptions{WordRegexp: true, OnlyMatching: true},
+			expected: "foo\n",
+		},
+		{
+			name:     "word regexp unicode letters",
+			input:    "привет\nпри вет",
+			patterns: []string{"при"},
+			opts:     domain.GrepOptions{WordRegexp: true},
+			expected: "при вет\n",
+		},
+		{
+			name:     "word regexp ignore case",
+			input:    "ID\nvalID",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{WordRegexp: true, IgnoreCase: true},
+			expected: "ID\n",
+		},
+		{
+			name:     "word regexp invert",
+			input:    "valid\nid",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{WordRegexp: true, InvertMatch: true},
+			expected: "valid\n",
+		},
+		{
+			name:     "fixed word regexp",
+			input:    "a.b\nxa.b\na.b.c\na.bx",
+			patterns: []string{"a.b"},
+			opts:     domain.GrepOptions{WordRegexp: true, FixedStrings: true},
+			expected: "a.b\na.b.c\n",
+		},
+		{
+			name:     "fixed word regexp retries later occurrence",
+			input:    "valid id",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{WordRegexp: true, FixedStrings: true, OnlyMatching: true, LineNumber: true},
+			expected: "1:id\n",
+		},
+		{
+			name:     "fixed word regexp ignore case",
+			input:    "Id\nvalId",
+			patterns: []string{"iD"},
+			opts:     domain.GrepOptions{WordRegexp: true, FixedStrings: true, IgnoreCase: true},
+			expected: "Id\n",
+		},
+		{
+			name:     "line regexp",
+			input:    "id\nid x\nx id",
+			patterns: []string{"id", "x.*"},
+			opts:     domain.GrepOptions{LineRegexp: true},
+			expected: "id\nx id\n",
+		},
+		{
+			name:     "line regexp ignore case",
+			input:    "ID\nid x",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{LineRegexp: true, IgnoreCase: true},
+			expected: "ID\n",
+		},
+		{
+			name:     "fixed line regexp",
+			input:    "a.b\na.bc\nA.B",
+			patterns: []string{"a.b"},
+			opts:     domain.GrepOptions{LineRegexp: true, FixedStrings: true},
+			expected: "a.b\n",
+		},
+		{
+			name:     "fixed line regexp empty pattern matches empty line",
+			input:    "a\n\nb",
+			patterns: []string{""},
+			opts:     domain.GrepOptions{LineRegexp: true, FixedStrings: true, LineNumber: true},
+			expected: "2:\n",
+		},
+		{
+			name:     "line regexp takes precedence over word regexp",
+			input:    "id\nid x",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{LineRegexp: true, WordRegexp: true},
+			expected: "id\n",
+		},
+		{
+			name:     "word regexp count",
+			input:    "id\nvalid\nid id",
+			patterns: []string{"id"},
+			opts:     domain.GrepOptions{WordRegexp: true, Count: true},
+			expected: "2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewMatcherPatterns(tt.patterns, tt.opts)
+			require.NoError(t, err, "Failed to create matcher")
+
+			var out strings.Builder
+			require.NoError(t, matcher.Search(strings.NewReader(tt.input), &out))
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}